@@ -0,0 +1,173 @@
+package struc
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// 字段对齐与定长记录
+//
+// align=N 使字段从所在结构体起始位置的 N 字节整数倍处开始，之前的空隙以零填充；
+// 与 Options.ByteAlign 不同，它只影响声明了 align 的字段的起始偏移，不改变字段本身的大小。
+//
+// 结构体级别的选项写在 `_ struct{}` 标记字段上，例如磁盘扇区记录：
+//
+//	type Sector struct {
+//		_       struct{} `struc:"size=512"`
+//		Header  uint32
+//		Payload []byte `struc:"[]byte,sizefrom=Header"`
+//	}
+//
+// size=N 打包时将记录以零填充到 N 字节，内容超过 N 字节时报错；解包时跳过记录末尾的填充。
+
+// handleAlignTag 处理 align= 标签
+func handleAlignTag(fieldDesc *Field, fieldTag *strucTag, field reflect.StructField) error {
+	if fieldTag.Size > 0 {
+		return fmt.Errorf("struc: `size=%d` is only valid on a `_ struct{}` marker field (field `%s`)", fieldTag.Size, field.Name)
+	}
+	if fieldTag.Align <= 1 {
+		return nil
+	}
+	if fieldDesc.BitSize > 0 {
+		return fmt.Errorf("struc: bitfield `%s` cannot be aligned", field.Name)
+	}
+	fieldDesc.Align = fieldTag.Align
+	return nil
+}
+
+// parseStructMarker 解析 `_` 字段上的结构体级别选项，不需要在打包时处理的选项返回 nil
+// 定长记录的标记字段作为长度为 0 的填充字段保存在字段集合中，不占用任何字节；
+// 字节序在解析其它字段时由 structByteOrder 读取
+func parseStructMarker(field reflect.StructField) (*Field, error) {
+	fieldTag := parseStrucTag(field.Tag)
+	if fieldTag.err != nil {
+		return nil, fmt.Errorf("%w (field `%s`)", fieldTag.err, field.Name)
+	}
+	if fieldTag.Size <= 0 && !fieldTag.HasOrder {
+		return nil, nil
+	}
+	if field.Type.Kind() != reflect.Struct || field.Type.NumField() != 0 {
+		return nil, fmt.Errorf("struc: struct options must be declared on a `_ struct{}` field, got %v", field.Type)
+	}
+	if fieldTag.Size <= 0 {
+		return nil, nil
+	}
+
+	marker := acquireField()
+	marker.Name = field.Name
+	marker.Type = Pad
+	marker.Length = 0
+	marker.kind = reflect.Struct
+	marker.recordSize = fieldTag.Size
+	return marker, nil
+}
+
+// recordSize 返回结构体声明的定长记录大小，0 表示不定长
+func (f Fields) recordSize() int {
+	for _, field := range f {
+		if field != nil && field.recordSize > 0 {
+			return field.recordSize
+		}
+	}
+	return 0
+}
+
+// needsPosition 判断解包时是否需要跟踪结构体内的读取位置
+func (f Fields) needsPosition(options *Options) bool {
+	if options.Layout != nil {
+		return true
+	}
+	for _, field := range f {
+		if field != nil && (field.Align > 1 || field.recordSize > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// alignPadding 返回字段从 position 开始时需要在其之前填充的字节数
+func (f *Field) alignPadding(position int, options *Options) int {
+	align := f.alignment(options)
+	if align <= 1 {
+		return 0
+	}
+	return (align - position%align) % align
+}
+
+// fieldPadding 返回存在的字段在 position 处需要的对齐填充
+func (f *Field) fieldPadding(structValue reflect.Value, position int, options *Options) (int, error) {
+	padding := f.alignPadding(position, options)
+	if padding == 0 {
+		return 0, nil
+	}
+	present, err := f.present(structValue)
+	if err != nil || !present {
+		return 0, err
+	}
+	return padding, nil
+}
+
+// fixedSizeof 返回按顺序存放的字段（含对齐填充）占用的字节数
+func (f Fields) fixedSizeof(structValue reflect.Value, options *Options) int {
+	position := 0
+	for i, field := range f {
+		if field == nil || field.Offsetfrom != nil {
+			continue
+		}
+		// 求值失败时由 Pack 报告错误
+		padding, _ := field.fieldPadding(structValue, position, options)
+		position += padding + f.fieldSizeof(structValue, i, field, options)
+	}
+	return position + f.tailPadding(position, options)
+}
+
+// padRecord 将打包后的记录以零填充到声明的大小
+func (f Fields) padRecord(buffer []byte, position int) (int, error) {
+	size := f.recordSize()
+	if size <= 0 {
+		return position, nil
+	}
+	if position > size {
+		return position, fmt.Errorf("struc: record content of %d bytes exceeds `size=%d`", position, size)
+	}
+	clear(buffer[position:size])
+	return size, nil
+}
+
+// skipRecordPadding 解包时跳过记录末尾的填充
+// 有按偏移存放的字段时，数据源定位到记录末尾
+func (f Fields) skipRecordPadding(reader io.Reader, consumed int, source offsetSource, base int64) error {
+	size := f.recordSize()
+	if size <= 0 {
+		return nil
+	}
+	if source != nil {
+		end, err := source.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		consumed = int(end - base)
+	}
+	if consumed > size {
+		return fmt.Errorf("struc: record content of %d bytes exceeds `size=%d`", consumed, size)
+	}
+	if source != nil {
+		_, err := source.Seek(base+int64(size), io.SeekStart)
+		return err
+	}
+	_, err := io.CopyN(io.Discard, reader, int64(size-consumed))
+	return err
+}
+
+// positionReader 记录已读取的字节数
+type positionReader struct {
+	reader   io.Reader
+	position int
+}
+
+func (r *positionReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.position += n
+	return n, err
+}
@@ -0,0 +1,145 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type alignEntry struct {
+	Tag   uint8
+	Value uint32 `struc:"uint32,align=4"`
+	Flags uint8
+	Name  []byte `struc:"[]byte,prefix=uint8,align=8"`
+	Tail  uint16 `struc:"uint16,align=2"`
+}
+
+type alignSector struct {
+	_       struct{} `struc:"size=16"`
+	Kind    uint8
+	Length  uint8 `struc:"uint8,sizeof=Payload"`
+	Payload []byte
+}
+
+type alignDisk struct {
+	Sectors [2]alignSector
+	Trailer uint8
+}
+
+func TestAlignFields(t *testing.T) {
+	in := &alignEntry{Tag: 1, Value: 0x01020304, Flags: 2, Name: []byte("ab"), Tail: 0x0506}
+	want := []byte{
+		1, 0, 0, 0, 1, 2, 3, 4,
+		2, 0, 0, 0, 0, 0, 0, 0,
+		2, 'a', 'b', 0, 5, 6,
+	}
+
+	size, err := Sizeof(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != len(want) {
+		t.Fatalf("sizeof: got %d, want %d", size, len(want))
+	}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), want)
+	}
+
+	out := &alignEntry{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestAlignRecordSize(t *testing.T) {
+	in := &alignDisk{
+		Sectors: [2]alignSector{
+			{Kind: 1, Payload: []byte("hello")},
+			{Kind: 2, Payload: []byte("x")},
+		},
+		Trailer: 0xff,
+	}
+
+	size, err := Sizeof(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 33 {
+		t.Fatalf("sizeof: got %d, want 33", size)
+	}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if len(data) != 33 || data[16] != 2 || data[32] != 0xff {
+		t.Fatalf("pack: got %x", data)
+	}
+	if !bytes.Equal(data[7:16], make([]byte, 9)) {
+		t.Fatalf("record padding not zeroed: %x", data[:16])
+	}
+
+	out := &alignDisk{}
+	if err := Unpack(bytes.NewReader(data), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestAlignRecordOverflow(t *testing.T) {
+	in := &alignSector{Payload: make([]byte, 15)}
+	var buf bytes.Buffer
+	err := Pack(&buf, in)
+	if err == nil || !strings.Contains(err.Error(), "exceeds `size=16`") {
+		t.Fatalf("expected overflow error, got %v", err)
+	}
+
+	data := append([]byte{1, 15}, make([]byte, 15)...)
+	err = Unpack(bytes.NewReader(data), &alignSector{})
+	if err == nil || !strings.Contains(err.Error(), "exceeds `size=16`") {
+		t.Fatalf("expected overflow error, got %v", err)
+	}
+}
+
+func TestAlignTagErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"invalid align", &struct {
+			A uint8 `struc:"uint8,align=x"`
+		}{}, "invalid alignment"},
+		{"size on field", &struct {
+			A uint8 `struc:"uint8,size=4"`
+		}{}, "only valid on a `_ struct{}` marker"},
+		{"marker type", &struct {
+			_ [4]byte `struc:"size=4"`
+			A uint8
+		}{}, "`_ struct{}` field"},
+		{"bitfield", &struct {
+			A uint8 `struc:"uint8,bits=4,align=2"`
+			B uint8 `struc:"uint8,bits=4"`
+		}{}, "cannot be aligned"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sizeof(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
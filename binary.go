@@ -122,7 +122,7 @@ func (b binaryFallback) Pack(buf []byte, val reflect.Value, options *Options) (i
 
 	// 获取字节序，默认使用大端序
 	// Get byte order, use big-endian by default
-	byteOrder := options.Order
+	byteOrder := options.defaultOrder()
 	if byteOrder == nil {
 		byteOrder = binary.BigEndian
 	}
@@ -138,7 +138,7 @@ func (b binaryFallback) Pack(buf []byte, val reflect.Value, options *Options) (i
 func (b binaryFallback) Unpack(reader io.Reader, val reflect.Value, options *Options) error {
 	// 获取字节序，默认使用大端序
 	// Get byte order, use big-endian by default
-	byteOrder := options.Order
+	byteOrder := options.defaultOrder()
 	if byteOrder == nil {
 		byteOrder = binary.BigEndian
 	}
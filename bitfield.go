@@ -0,0 +1,192 @@
+package struc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// 位域（bitfield）支持
+//
+// 标签示例：
+//
+//	type IPv4Header struct {
+//		Version int `struc:"uint8,bits=4"`
+//		IHL     int `struc:"uint8,bits=4"`
+//	}
+//
+// 连续的位域字段共享同一个存储单元，存储单元的宽度由第一个字段的类型决定。
+// 当存储单元的剩余位数不足、类型宽度不同、字节序或位序不同时，会开启一个新的存储单元。
+// 默认按 MSB 优先排列（第一个字段占用最高位），可通过 bitorder=lsb 改为 LSB 优先。
+
+// bitfieldUnitTypes 定义了位域存储单元的有符号类型到无符号类型的映射
+var bitfieldUnitTypes = map[Type]Type{
+	Bool:   Uint8,
+	Int8:   Uint8,
+	Uint8:  Uint8,
+	Int16:  Uint16,
+	Uint16: Uint16,
+	Int32:  Uint32,
+	Uint32: Uint32,
+	Int64:  Uint64,
+	Uint64: Uint64,
+}
+
+// handleBitfieldTag 处理字段的 bits 标签
+// 校验位域字段的类型和宽度，具体的位偏移在 layoutBitfields 中计算
+func handleBitfieldTag(fieldDesc *Field, fieldTag *strucTag, field reflect.StructField) error {
+	if fieldTag.Bits == 0 {
+		return nil
+	}
+	if fieldDesc.IsSlice || fieldDesc.IsPointer {
+		return fmt.Errorf("struc: bitfield `%s` must not be a slice, array or pointer", field.Name)
+	}
+	unitType, ok := bitfieldUnitTypes[fieldDesc.Type]
+	if !ok {
+		return fmt.Errorf("struc: bitfield `%s` has unsupported type %v", field.Name, fieldDesc.Type)
+	}
+	switch fieldDesc.kind {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return fmt.Errorf("struc: bitfield `%s` must be a bool or integer field", field.Name)
+	}
+	if unitBits := unitType.Size() * 8; fieldTag.Bits > unitBits {
+		return fmt.Errorf("struc: bitfield `%s` width %d exceeds %d-bit %v", field.Name, fieldTag.Bits, unitBits, fieldDesc.Type)
+	}
+
+	fieldDesc.BitSize = fieldTag.Bits
+	fieldDesc.bitUnit = unitType
+	fieldDesc.bitLSB = fieldTag.BitLSB
+	return nil
+}
+
+// layoutBitfields 将连续的位域字段分组到存储单元中，并计算每个字段的位偏移
+// 被跳过的字段（nil）不占用二进制空间，因此不会打断位域分组
+func layoutBitfields(fields Fields) {
+	var group []*Field
+	usedBits := 0
+
+	flush := func() {
+		if len(group) == 0 {
+			return
+		}
+		group[0].bitFirst = true
+		group[len(group)-1].bitLast = true
+		group = group[:0]
+		usedBits = 0
+	}
+
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+		if field.BitSize == 0 {
+			flush()
+			continue
+		}
+		if len(group) > 0 {
+			head := group[0]
+			if head.bitUnit != field.bitUnit || head.bitLSB != field.bitLSB ||
+				head.ByteOrder != field.ByteOrder || usedBits+field.BitSize > field.bitUnit.Size()*8 {
+				flush()
+			}
+		}
+
+		unitBits := field.bitUnit.Size() * 8
+		if field.bitLSB {
+			field.bitOffset = usedBits
+		} else {
+			field.bitOffset = unitBits - usedBits - field.BitSize
+		}
+		usedBits += field.BitSize
+		group = append(group, field)
+	}
+	flush()
+}
+
+// bitfieldSize 返回位域字段占用的字节数
+// 存储单元的大小只计入分组中的最后一个字段，其余字段为 0
+func (f *Field) bitfieldSize() int {
+	if f.bitLast {
+		return f.bitUnit.Size()
+	}
+	return 0
+}
+
+// bitfieldMask 返回位域宽度对应的掩码
+func (f *Field) bitfieldMask() uint64 {
+	if f.BitSize >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<uint(f.BitSize) - 1
+}
+
+// packBitfield 将位域字段的值合并到当前存储单元中
+// 存储单元在分组的第一个字段时清零，在最后一个字段时才推进写入位置
+func (f *Field) packBitfield(buffer []byte, fieldValue reflect.Value, options *Options) (int, error) {
+	byteOrder := f.determineByteOrder(options)
+	if f.bitFirst {
+		memclr(buffer[:f.bitUnit.Size()])
+	}
+
+	value := f.getIntegerValue(fieldValue)
+	if err := f.checkBitfieldRange(value); err != nil {
+		return 0, err
+	}
+
+	unit := f.readInteger(buffer, f.bitUnit, byteOrder)
+	unit |= (value & f.bitfieldMask()) << uint(f.bitOffset)
+	if err := f.writeInteger(buffer, unit, f.bitUnit, byteOrder); err != nil {
+		return 0, err
+	}
+	return f.bitfieldSize(), nil
+}
+
+// checkBitfieldRange 检查值是否能用位域宽度表示
+func (f *Field) checkBitfieldRange(value uint64) error {
+	if f.BitSize >= 64 {
+		return nil
+	}
+	switch f.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		signed := int64(value)
+		if f.Type == Int8 || f.Type == Int16 || f.Type == Int32 || f.Type == Int64 {
+			limit := int64(1) << uint(f.BitSize-1)
+			if signed < -limit || signed >= limit {
+				return fmt.Errorf("struc: value %d overflows %d-bit signed bitfield %s", signed, f.BitSize, f.Name)
+			}
+			return nil
+		}
+		if signed < 0 || value > f.bitfieldMask() {
+			return fmt.Errorf("struc: value %d overflows %d-bit bitfield %s", signed, f.BitSize, f.Name)
+		}
+	default:
+		if value > f.bitfieldMask() {
+			return fmt.Errorf("struc: value %d overflows %d-bit bitfield %s", value, f.BitSize, f.Name)
+		}
+	}
+	return nil
+}
+
+// unpackBitfield 从存储单元中提取位域字段的值
+// 有符号类型会按位域宽度进行符号扩展
+func (f *Field) unpackBitfield(unit uint64, fieldValue reflect.Value) {
+	value := (unit >> uint(f.bitOffset)) & f.bitfieldMask()
+
+	switch f.Type {
+	case Int8, Int16, Int32, Int64:
+		if shift := uint(64 - f.BitSize); shift > 0 {
+			value = uint64(int64(value<<shift) >> shift)
+		}
+	}
+
+	switch f.kind {
+	case reflect.Bool:
+		fieldValue.SetBool(value != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fieldValue.SetInt(int64(value))
+	default:
+		fieldValue.SetUint(value)
+	}
+}
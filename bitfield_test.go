@@ -0,0 +1,121 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type bitfieldHeader struct {
+	Version  int    `struc:"uint8,bits=4"`
+	IHL      int    `struc:"uint8,bits=4"`
+	Flags    uint16 `struc:"uint16,bits=3"`
+	Fragment uint16 `struc:"uint16,bits=13"`
+	TTL      uint8
+}
+
+func TestBitfieldMSB(t *testing.T) {
+	var buf bytes.Buffer
+	in := &bitfieldHeader{Version: 4, IHL: 5, Flags: 2, Fragment: 0x123, TTL: 64}
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x45, 0x41, 0x23, 64}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("bitfield pack: got %x, want %x", buf.Bytes(), want)
+	}
+	out := &bitfieldHeader{}
+	if err := Unpack(&buf, out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("bitfield round trip: got %+v, want %+v", out, in)
+	}
+	if size, err := Sizeof(in); err != nil || size != len(want) {
+		t.Fatalf("bitfield sizeof: got %d (%v), want %d", size, err, len(want))
+	}
+}
+
+type bitfieldLSB struct {
+	FIN bool  `struc:"bool,bits=1,bitorder=lsb"`
+	SYN bool  `struc:"bool,bits=1,bitorder=lsb"`
+	RST bool  `struc:"bool,bits=1,bitorder=lsb"`
+	Res uint8 `struc:"uint8,bits=5,bitorder=lsb"`
+	Val int8  `struc:"int8,bits=4"`
+	Tag int8  `struc:"int8,bits=4"`
+}
+
+func TestBitfieldLSBAndSigned(t *testing.T) {
+	var buf bytes.Buffer
+	in := &bitfieldLSB{FIN: true, RST: true, Res: 3, Val: -2, Tag: 7}
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x1d, 0xe7}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("bitfield pack: got %x, want %x", buf.Bytes(), want)
+	}
+	out := &bitfieldLSB{}
+	if err := Unpack(&buf, out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("bitfield round trip: got %+v, want %+v", out, in)
+	}
+}
+
+type bitfieldLittle struct {
+	Low  uint16 `struc:"uint16,little,bits=4,bitorder=lsb"`
+	High uint16 `struc:"uint16,little,bits=12,bitorder=lsb"`
+}
+
+func TestBitfieldLittleEndianUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Pack(&buf, &bitfieldLittle{Low: 0xa, High: 0x123}); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x3a, 0x12}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("bitfield pack: got %x, want %x", buf.Bytes(), want)
+	}
+}
+
+func TestBitfieldOverflow(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Pack(&buf, &bitfieldHeader{Version: 16}); err == nil {
+		t.Fatal("failed to error on bitfield overflow")
+	}
+	if err := Pack(&buf, &bitfieldLSB{Val: -9}); err == nil {
+		t.Fatal("failed to error on signed bitfield overflow")
+	}
+}
+
+type bitfieldTooWide struct {
+	A uint8 `struc:"uint8,bits=9"`
+}
+
+type bitfieldBadWidth struct {
+	A uint8 `struc:"uint8,bits=x"`
+}
+
+type bitfieldFloat struct {
+	A float32 `struc:"float32,bits=3"`
+}
+
+func TestBitfieldParseErrors(t *testing.T) {
+	for _, v := range []interface{}{&bitfieldTooWide{}, &bitfieldBadWidth{}, &bitfieldFloat{}} {
+		if err := parseTest(v); err == nil {
+			t.Errorf("failed to error on invalid bitfield %T", v)
+		}
+	}
+}
+
+func TestBitfieldFormatString(t *testing.T) {
+	format, err := GetFormatString(&bitfieldHeader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != ">BHB" {
+		t.Fatalf("bitfield format: got %q, want %q", format, ">BHB")
+	}
+}
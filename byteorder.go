@@ -0,0 +1,101 @@
+package struc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// 结构体级别的字节序
+//
+// 结构体可以通过 `_ struct{}` 标记字段或 StrucByteOrder 方法声明默认字节序：
+//
+//	type Header struct {
+//		_     struct{} `struc:"little"`
+//		Magic uint32   `struc:"uint32,big"` // 字段标签优先
+//		Size  uint32                        // 使用结构体的默认字节序
+//	}
+//
+// 字节序的优先级从高到低：
+//
+//  1. Options.OrderMode 为 OrderForce（默认）时的 Options.Order
+//  2. 字段标签中的 big/little
+//  3. 结构体自身声明的默认字节序（StrucByteOrder 方法优先于标记字段）
+//  4. 外层结构体的默认字节序或嵌套字段标签中的 big/little，逐层向内传递
+//  5. Options.OrderMode 为 OrderDefault 时的 Options.Order
+//  6. Options.Layout 的字节序
+//  7. 大端序
+
+// ByteOrderer 由声明默认字节序的结构体实现
+type ByteOrderer interface {
+	StrucByteOrder() binary.ByteOrder
+}
+
+var byteOrdererType = reflect.TypeOf((*ByteOrderer)(nil)).Elem()
+
+// nestedFieldsKey 是按外层字节序解析的嵌套结构体缓存键
+type nestedFieldsKey struct {
+	structType reflect.Type
+	order      binary.ByteOrder
+}
+
+// parsedNestedFieldCache 存储继承了外层字节序的嵌套结构体字段 (并发安全)
+var parsedNestedFieldCache = sync.Map{}
+
+// structByteOrder 返回结构体声明的默认字节序，没有声明时返回 nil
+func structByteOrder(structType reflect.Type) (binary.ByteOrder, error) {
+	if reflect.PointerTo(structType).Implements(byteOrdererType) {
+		if order := reflect.New(structType).Interface().(ByteOrderer).StrucByteOrder(); order != nil {
+			return order, nil
+		}
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Name != "_" {
+			continue
+		}
+		fieldTag := parseStrucTag(field.Tag)
+		if fieldTag.err != nil {
+			return nil, fmt.Errorf("%w (field `%s`)", fieldTag.err, field.Name)
+		}
+		if fieldTag.HasOrder {
+			return fieldTag.Order, nil
+		}
+	}
+	return nil, nil
+}
+
+// applyStructByteOrder 为没有在标签中声明字节序的字段应用结构体的默认字节序
+func applyStructByteOrder(fieldDesc *Field, fieldTag *strucTag, defaultOrder binary.ByteOrder) {
+	switch {
+	case fieldTag.HasOrder:
+		fieldDesc.orderDeclared = true
+	case defaultOrder != nil:
+		fieldDesc.ByteOrder = defaultOrder
+		fieldDesc.orderDeclared = true
+	}
+}
+
+// parseNestedFields 解析嵌套结构体的字段
+// order 为外层传递的默认字节序，nil 时与顶层结构体共用缓存；
+// 依赖外层结构体（scope 非 nil）的字段随外层结构体一起缓存，不单独缓存
+func parseNestedFields(structValue reflect.Value, order binary.ByteOrder, scope *parseScope) (Fields, error) {
+	if scope != nil {
+		return parseFieldsLocked(structValue, order, scope)
+	}
+	if order == nil {
+		return parseFields(structValue)
+	}
+	key := nestedFieldsKey{structType: structValue.Type(), order: order}
+	if cached, ok := parsedNestedFieldCache.Load(key); ok {
+		return cached.(Fields), nil
+	}
+
+	fields, err := parseFieldsLocked(structValue, order, nil)
+	if err != nil {
+		return nil, err
+	}
+	parsedNestedFieldCache.Store(key, fields)
+	return fields, nil
+}
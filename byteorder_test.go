@@ -0,0 +1,133 @@
+package struc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type byteOrderInner struct {
+	A uint16
+}
+
+type byteOrderBigInner struct {
+	_ struct{} `struc:"big"`
+	A uint16
+}
+
+type byteOrderRecord struct {
+	_      struct{} `struc:"little"`
+	Magic  uint16   `struc:"uint16,big"`
+	Size   uint16
+	Inner  byteOrderInner
+	Big    byteOrderBigInner
+	Tagged byteOrderInner `struc:"big"`
+}
+
+type byteOrderMethod struct {
+	A uint16
+	B uint16 `struc:"uint16,big"`
+}
+
+func (byteOrderMethod) StrucByteOrder() binary.ByteOrder {
+	return binary.LittleEndian
+}
+
+type byteOrderPlain struct {
+	A uint16
+	B uint16 `struc:"uint16,big"`
+}
+
+func TestStructByteOrder(t *testing.T) {
+	in := &byteOrderRecord{
+		Magic:  0x0102,
+		Size:   0x0304,
+		Inner:  byteOrderInner{A: 0x0506},
+		Big:    byteOrderBigInner{A: 0x0708},
+		Tagged: byteOrderInner{A: 0x090a},
+	}
+	want := []byte{1, 2, 4, 3, 6, 5, 7, 8, 9, 10}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), want)
+	}
+
+	out := &byteOrderRecord{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+
+	// 未继承字节序的结构体仍为大端序，不受嵌套解析的影响
+	buf.Reset()
+	if err := Pack(&buf, &byteOrderInner{A: 0x0506}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{5, 6}) {
+		t.Fatalf("standalone inner: got %x", buf.Bytes())
+	}
+}
+
+func TestStructByteOrderMethod(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Pack(&buf, &byteOrderMethod{A: 0x0102, B: 0x0304}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{2, 1, 3, 4}; !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), want)
+	}
+}
+
+func TestByteOrderOptionsMode(t *testing.T) {
+	in := &byteOrderPlain{A: 0x0102, B: 0x0304}
+	tests := []struct {
+		name    string
+		options *Options
+		want    []byte
+	}{
+		{"none", &Options{}, []byte{1, 2, 3, 4}},
+		{"force", &Options{Order: binary.LittleEndian}, []byte{2, 1, 4, 3}},
+		{"default", NewOptionsBuilder().WithDefaultByteOrder(binary.LittleEndian).MustBuild(), []byte{2, 1, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := PackWithOptions(&buf, in, tt.options); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Fatalf("pack: got %x, want %x", buf.Bytes(), tt.want)
+			}
+			out := &byteOrderPlain{}
+			if err := UnpackWithOptions(bytes.NewReader(tt.want), out, tt.options); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(in, out) {
+				t.Fatalf("round trip: got %+v, want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestStructByteOrderErrors(t *testing.T) {
+	_, err := Sizeof(&struct {
+		_ uint8 `struc:"little"`
+		A uint16
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "`_ struct{}` field") {
+		t.Fatalf("expected marker type error, got %v", err)
+	}
+
+	_, err = SizeofWithOptions(&byteOrderPlain{}, &Options{OrderMode: OrderMode(7)})
+	if err == nil || !strings.Contains(err.Error(), "OrderMode") {
+		t.Fatalf("expected order mode error, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package struc
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// 字节长度引用
+//
+// sizeof/sizefrom 记录的是元素个数；bytesof=Body 记录 Body 打包后的字节数，
+// 适用于嵌套结构体、变长结构体切片和自定义类型等没有元素个数概念的字段。
+// 数据字段上的 sizefrom=Len,bytes 表示 Len 保存的是字节数；bytesof 会自动为目标字段启用该模式。
+//
+// 解包时数据字段只能读取 Len 字节：切片和字符串读取窗口内的全部元素，
+// 其它字段解包后丢弃窗口内未读取的字节。
+
+// handleBytesofTag 处理长度字段的 bytesof= 标签以及数据字段的 bytes 选项
+func handleBytesofTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField, fields Fields, sizeofMap map[string][]int) error {
+	if fieldTag.Bytesof != "" {
+		target, ok := structType.FieldByName(fieldTag.Bytesof)
+		if !ok {
+			return fmt.Errorf("struc: `bytesof=%s` field does not exist", fieldTag.Bytesof)
+		}
+		if len(target.Index) != 1 {
+			return fmt.Errorf("struc: `bytesof=%s` must refer to a direct field", fieldTag.Bytesof)
+		}
+		if fieldDesc.Sizeof != nil {
+			return fmt.Errorf("struc: field `%s` cannot use both sizeof and bytesof", field.Name)
+		}
+		switch fieldDesc.kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return fmt.Errorf("struc: bytesof field `%s` must be an integer", field.Name)
+		}
+		fieldDesc.Bytesof = target.Index
+		sizeofMap[fieldTag.Bytesof] = field.Index
+	}
+
+	if fieldDesc.Sizefrom == nil {
+		if fieldTag.Bytes {
+			return fmt.Errorf("struc: `bytes` requires `sizefrom=` (field `%s`)", field.Name)
+		}
+		return nil
+	}
+	if !fieldTag.Bytes {
+		if fieldDesc.sizefromUp > 0 || len(fieldDesc.Sizefrom) > 1 {
+			return nil
+		}
+		source := fieldDesc.Sizefrom[0]
+		if source >= field.Index[0] || fields[source] == nil || fields[source].Bytesof == nil {
+			return nil
+		}
+	}
+
+	switch fieldDesc.Type {
+	case CString, Pad, Uvarint, Varint, Sleb128:
+		if fieldDesc.IsSlice {
+			return fmt.Errorf("struc: byte-length field `%s` must have fixed-size elements, got %s", field.Name, fieldDesc.Type)
+		}
+	}
+	fieldDesc.byteLength = true
+	return nil
+}
+
+// bytesofLength 返回 bytesof 字段在打包时应写入的字节数
+func (f Fields) bytesofLength(structValue reflect.Value, field *Field, options *Options) int {
+	index := field.Bytesof[0]
+	target := f[index]
+	if target == nil {
+		return 0
+	}
+	return f.fieldSizeof(structValue, index, target, options)
+}
+
+// unpackByteLength 在 byteLength 字节的窗口内解包字段，并丢弃窗口内未读取的字节
+func (f Fields) unpackByteLength(reader io.Reader, structValue reflect.Value, fieldValue reflect.Value, field *Field, byteLength int, options *Options, scratch *scratchArena) error {
+	if byteLength < 0 {
+		return fmt.Errorf("struc: negative byte length %d for field `%s`", byteLength, field.Name)
+	}
+	window := &io.LimitedReader{R: reader, N: int64(byteLength)}
+
+	var err error
+	switch {
+	case field.Type == Union:
+		err = f.unpackUnion(window, structValue, fieldValue, field, options, scratch)
+	case field.IsSlice && !field.IsArray, field.kind == reflect.String && field.Type == String:
+		err = f.unpackRest(window, fieldValue, field, options, scratch)
+	case field.Type == Struct:
+		err = f.unpackStruct(window, fieldValue, field, field.Length, options, scratch)
+	case field.Type == CustomType:
+		err = f.unpackBasicType(window, fieldValue, field, byteLength, options, scratch)
+	default:
+		err = f.unpackBasicType(window, fieldValue, field, field.Length, options, scratch)
+	}
+	if err != nil {
+		if window.N == 0 && (err == io.EOF || err == io.ErrUnexpectedEOF) {
+			return fmt.Errorf("struc: field `%s` does not fit in its %d-byte length: %w", field.Name, byteLength, io.ErrUnexpectedEOF)
+		}
+		return err
+	}
+
+	if window.N > 0 {
+		if _, err := io.CopyN(io.Discard, window, window.N); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type bytesofRecord struct {
+	ID   uint8
+	Name []byte `struc:"pstring8"`
+}
+
+type bytesofBody struct {
+	Version uint8
+	Label   []byte `struc:"pstring8"`
+}
+
+type bytesofMessage struct {
+	BodyLen   uint16 `struc:"uint16,bytesof=Body"`
+	Body      bytesofBody
+	ListLen   uint8 `struc:"uint8,bytesof=Records"`
+	Records   []bytesofRecord
+	WordBytes uint8
+	Words     []uint16 `struc:"[]uint16,sizefrom=WordBytes,bytes"`
+	Tail      uint8
+}
+
+func TestBytesofFields(t *testing.T) {
+	in := &bytesofMessage{
+		Body:      bytesofBody{Version: 2, Label: []byte("abc")},
+		Records:   []bytesofRecord{{ID: 1, Name: []byte("x")}, {ID: 2, Name: []byte("yz")}},
+		WordBytes: 4,
+		Words:     []uint16{0x0102, 0x0304},
+		Tail:      9,
+	}
+	want := []byte{
+		0, 5, 2, 3, 'a', 'b', 'c',
+		7, 1, 1, 'x', 2, 2, 'y', 'z',
+		4, 1, 2, 3, 4,
+		9,
+	}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), want)
+	}
+	if in.BodyLen != 5 || in.ListLen != 7 {
+		t.Fatalf("byte lengths not written back: %+v", in)
+	}
+
+	out := &bytesofMessage{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestBytesofSkipsUnknownTrailingBytes(t *testing.T) {
+	// 新版本的结构体可能比当前定义更长，多余的字节应被跳过
+	data := []byte{
+		0, 7, 2, 3, 'a', 'b', 'c', 0xee, 0xee,
+		0,
+		0,
+		9,
+	}
+	out := &bytesofMessage{}
+	if err := Unpack(bytes.NewReader(data), out); err != nil {
+		t.Fatal(err)
+	}
+	if string(out.Body.Label) != "abc" || len(out.Records) != 0 || out.Tail != 9 {
+		t.Fatalf("unpack: got %+v", out)
+	}
+
+	short := []byte{0, 3, 2, 3, 'a', 'b', 'c'}
+	err := Unpack(bytes.NewReader(short), &bytesofMessage{})
+	if err == nil || !strings.Contains(err.Error(), "does not fit") {
+		t.Fatalf("expected length error, got %v", err)
+	}
+}
+
+func TestBytesofTagErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"sizeof struct", &struct {
+			N    uint8 `struc:"uint8,sizeof=Body"`
+			Body bytesofBody
+		}{}, "use `bytesof=Body`"},
+		{"bytes without sizefrom", &struct {
+			Data [2]byte `struc:"[2]byte,bytes"`
+		}{}, "requires `sizefrom=`"},
+		{"not integer", &struct {
+			N    float32 `struc:"float32,bytesof=Body"`
+			Body bytesofBody
+		}{}, "must be an integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sizeof(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package struc
+
+import (
+	"fmt"
+	"hash/adler32"
+	"hash/crc32"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ChecksumFunc 计算一段字节的校验和
+// 结果按校验和字段的宽度截断后写入
+type ChecksumFunc func(data []byte) uint64
+
+// checksumRegistry 保存已注册的校验和算法 (并发安全)
+var checksumRegistry = struct {
+	sync.RWMutex
+	funcs map[string]ChecksumFunc
+}{funcs: map[string]ChecksumFunc{
+	"crc32":        func(data []byte) uint64 { return uint64(crc32.ChecksumIEEE(data)) },
+	"crc32c":       func(data []byte) uint64 { return uint64(crc32.Checksum(data, crc32cTable)) },
+	"adler32":      func(data []byte) uint64 { return uint64(adler32.Checksum(data)) },
+	"crc16":        crc16Func(0xa001, 0x0000, true),  // CRC-16/ARC
+	"crc16-modbus": crc16Func(0xa001, 0xffff, true),  // CRC-16/MODBUS
+	"crc16-kermit": crc16Func(0x8408, 0x0000, true),  // CRC-16/KERMIT (CCITT, 低位优先)
+	"crc16-ccitt":  crc16Func(0x1021, 0xffff, false), // CRC-16/CCITT-FALSE
+	"crc16-xmodem": crc16Func(0x1021, 0x0000, false), // CRC-16/XMODEM
+	"sum8":         sumChecksum(0xff),
+	"sum16":        sumChecksum(0xffff),
+	"xor8":         xor8Checksum,
+	"inet":         internetChecksum,
+}}
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// RegisterChecksum 注册自定义校验和算法，名称可在 checksum= 标签中使用
+// 已解析过的结构体在解析时绑定算法，注册应在首次使用前完成
+func RegisterChecksum(name string, fn ChecksumFunc) error {
+	if name == "" || fn == nil {
+		return ErrTypeRegistrationf("checksum name and function must not be empty")
+	}
+	checksumRegistry.Lock()
+	defer checksumRegistry.Unlock()
+
+	if _, exists := checksumRegistry.funcs[name]; exists {
+		return ErrTypeRegistrationf("checksum '%s' already registered", name)
+	}
+	checksumRegistry.funcs[name] = fn
+	return nil
+}
+
+// lookupChecksum 查找校验和算法
+func lookupChecksum(name string) (ChecksumFunc, bool) {
+	checksumRegistry.RLock()
+	defer checksumRegistry.RUnlock()
+	fn, ok := checksumRegistry.funcs[name]
+	return fn, ok
+}
+
+// crc16Func 生成查表实现的 CRC16 算法
+// reflected 为 true 时 poly 为反转后的多项式，按低位优先处理
+func crc16Func(poly, init uint16, reflected bool) ChecksumFunc {
+	var table [256]uint16
+	for i := range table {
+		if reflected {
+			crc := uint16(i)
+			for bit := 0; bit < 8; bit++ {
+				if crc&1 != 0 {
+					crc = crc>>1 ^ poly
+				} else {
+					crc >>= 1
+				}
+			}
+			table[i] = crc
+		} else {
+			crc := uint16(i) << 8
+			for bit := 0; bit < 8; bit++ {
+				if crc&0x8000 != 0 {
+					crc = crc<<1 ^ poly
+				} else {
+					crc <<= 1
+				}
+			}
+			table[i] = crc
+		}
+	}
+
+	return func(data []byte) uint64 {
+		crc := init
+		for _, b := range data {
+			if reflected {
+				crc = crc>>8 ^ table[byte(crc)^b]
+			} else {
+				crc = crc<<8 ^ table[byte(crc>>8)^b]
+			}
+		}
+		return uint64(crc)
+	}
+}
+
+// sumChecksum 生成按字节累加并截断到 mask 的校验和
+func sumChecksum(mask uint64) ChecksumFunc {
+	return func(data []byte) uint64 {
+		var sum uint64
+		for _, b := range data {
+			sum += uint64(b)
+		}
+		return sum & mask
+	}
+}
+
+// xor8Checksum 计算所有字节的异或值
+func xor8Checksum(data []byte) uint64 {
+	var sum byte
+	for _, b := range data {
+		sum ^= b
+	}
+	return uint64(sum)
+}
+
+// internetChecksum 计算 RFC 1071 互联网校验和（16 位反码和的反码）
+func internetChecksum(data []byte) uint64 {
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(data[i])<<8 | uint32(data[i+1])
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return uint64(^uint16(sum))
+}
+
+// checksumSpec 描述校验和字段覆盖的字段范围
+type checksumSpec struct {
+	algorithm string       // 算法名称
+	fn        ChecksumFunc // 算法实现
+	first     int          // 覆盖的第一个字段索引
+	last      int          // 覆盖的最后一个字段索引（包含）
+}
+
+// handleChecksumTag 解析 checksum= 和 range= 标签
+// range=A:B 覆盖从 A 到 B（包含）的所有字段，range=A 只覆盖 A；
+// 省略 range 时覆盖校验和字段之前的所有字段。范围包含校验和字段自身时，其字节按 0 计算
+func handleChecksumTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField) error {
+	if fieldTag.Checksum == "" {
+		if fieldTag.Range != "" {
+			return fmt.Errorf("struc: `range=%s` requires `checksum=` (field `%s`)", fieldTag.Range, field.Name)
+		}
+		return nil
+	}
+
+	switch fieldDesc.Type {
+	case Int8, Int16, Int32, Int64, Uint8, Uint16, Uint32, Uint64:
+	default:
+		return fmt.Errorf("struc: checksum field `%s` must be a fixed-size integer", field.Name)
+	}
+	if fieldDesc.IsSlice || fieldDesc.IsPointer || fieldDesc.BitSize > 0 || fieldDesc.Sizeof != nil ||
+		fieldDesc.condition != nil || fieldDesc.constValue.IsValid() {
+		return fmt.Errorf("struc: checksum field `%s` must be a plain integer field", field.Name)
+	}
+
+	fn, ok := lookupChecksum(fieldTag.Checksum)
+	if !ok {
+		return fmt.Errorf("struc: unknown checksum algorithm `%s` (field `%s`)", fieldTag.Checksum, field.Name)
+	}
+
+	spec := &checksumSpec{algorithm: fieldTag.Checksum, fn: fn, first: 0, last: field.Index[0] - 1}
+	if fieldTag.Range != "" {
+		firstName, lastName, isRange := strings.Cut(fieldTag.Range, ":")
+		if !isRange {
+			lastName = firstName
+		}
+		first, ok := structType.FieldByName(firstName)
+		if !ok {
+			return fmt.Errorf("struc: `range=%s` field `%s` does not exist", fieldTag.Range, firstName)
+		}
+		last, ok := structType.FieldByName(lastName)
+		if !ok {
+			return fmt.Errorf("struc: `range=%s` field `%s` does not exist", fieldTag.Range, lastName)
+		}
+		spec.first, spec.last = first.Index[0], last.Index[0]
+	}
+	if spec.first > spec.last {
+		return fmt.Errorf("struc: checksum field `%s` covers no fields", field.Name)
+	}
+
+	fieldDesc.checksum = spec
+	return nil
+}
+
+// hasChecksums 判断字段集合中是否有校验和字段
+func (f Fields) hasChecksums() bool {
+	for _, field := range f {
+		if field != nil && field.checksum != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldSpan 记录字段内容在结构体数据中的起止偏移，不含字段之前的对齐填充
+type fieldSpan struct {
+	start int
+	end   int
+}
+
+// widenBitfieldSpans 将位域组中每个字段的起止偏移扩展为整个存储单元
+// 打包时存储单元记在组内最后一个字段上，解包时记在第一个字段上，扩展后两侧覆盖相同的字节
+func (f Fields) widenBitfieldSpans(spans []fieldSpan) {
+	first := -1
+	for i, field := range f {
+		if field == nil || field.BitSize == 0 {
+			continue
+		}
+		if field.bitFirst {
+			first = i
+		}
+		if !field.bitLast || first < 0 {
+			continue
+		}
+		unit := spans[first]
+		for j := first + 1; j <= i; j++ {
+			if spans[j].start < unit.start {
+				unit.start = spans[j].start
+			}
+			if spans[j].end > unit.end {
+				unit.end = spans[j].end
+			}
+		}
+		for j := first; j <= i; j++ {
+			if f[j] != nil {
+				spans[j] = unit
+			}
+		}
+		first = -1
+	}
+}
+
+// checksumValue 计算校验和字段覆盖范围的校验值
+// spans[i] 为字段 i 的起止偏移，覆盖范围从第一个字段的起始到最后一个字段的结束
+func (f Fields) checksumValue(data []byte, spans []fieldSpan, index int) uint64 {
+	field := f[index]
+	spec := field.checksum
+	first, last := spans[spec.first].start, spans[spec.last].end
+	covered := data[first:last]
+
+	// 校验和字段自身位于覆盖范围内时，计算时按 0 处理
+	start, end := spans[index].start, spans[index].end
+	if start >= first && end <= last && end > start {
+		zeroed := make([]byte, len(covered))
+		copy(zeroed, covered)
+		memclr(zeroed[start-first : end-first])
+		covered = zeroed
+	}
+
+	value := spec.fn(covered)
+	if size := field.Type.Size(); size < 8 {
+		value &= 1<<uint(size*8) - 1
+	}
+	return value
+}
+
+// fillChecksums 在所有字段写入后计算并回填校验和
+func (f Fields) fillChecksums(buffer []byte, spans []fieldSpan, structValue reflect.Value, options *Options) error {
+	for i, field := range f {
+		if field == nil || field.checksum == nil {
+			continue
+		}
+		value := f.checksumValue(buffer, spans, i)
+		if err := field.writeInteger(buffer[spans[i].start:], value, field.Type, field.determineByteOrder(options)); err != nil {
+			return err
+		}
+
+		fieldValue := structValue.Field(i)
+		switch fieldValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fieldValue.SetInt(int64(value))
+		default:
+			fieldValue.SetUint(value)
+		}
+	}
+	return nil
+}
+
+// verifyChecksums 校验解包时记录的原始字节
+func (f Fields) verifyChecksums(data []byte, spans []fieldSpan, structValue reflect.Value) error {
+	for i, field := range f {
+		if field == nil || field.checksum == nil {
+			continue
+		}
+		expected := f.checksumValue(data, spans, i)
+		actual := field.getIntegerValue(structValue.Field(i))
+		if size := field.Type.Size(); size < 8 {
+			actual &= 1<<uint(size*8) - 1
+		}
+		if expected != actual {
+			return NewError(ErrChecksumMismatch,
+				fmt.Sprintf("%s checksum mismatch in field `%s`: computed %#x, got %#x", field.checksum.algorithm, field.Name, expected, actual),
+				map[string]interface{}{"field": field.Name, "algorithm": field.checksum.algorithm, "expected": expected, "actual": actual})
+		}
+	}
+	return nil
+}
+
+// checksumRecorder 在解包时记录读取的原始字节，用于校验和验证
+type checksumRecorder struct {
+	reader io.Reader
+	data   []byte
+}
+
+// Read 实现 io.Reader 接口
+func (r *checksumRecorder) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.data = append(r.data, p[:n]...)
+	return n, err
+}
@@ -0,0 +1,253 @@
+package struc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"testing"
+)
+
+func TestChecksumAlgorithms(t *testing.T) {
+	check := []byte("123456789")
+	tests := []struct {
+		name string
+		want uint64
+	}{
+		{"crc32", 0xcbf43926},
+		{"crc32c", 0xe3069283},
+		{"adler32", 0x091e01de},
+		{"crc16", 0xbb3d},
+		{"crc16-modbus", 0x4b37},
+		{"crc16-kermit", 0x2189},
+		{"crc16-ccitt", 0x29b1},
+		{"crc16-xmodem", 0x31c3},
+		{"sum8", 0xdd},
+		{"sum16", 0x01dd},
+		{"xor8", 0x31},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, ok := lookupChecksum(tt.name)
+			if !ok {
+				t.Fatalf("checksum %s not registered", tt.name)
+			}
+			if got := fn(check); got != tt.want {
+				t.Fatalf("got %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
+
+type checksumPacket struct {
+	Magic   uint16
+	Length  uint8 `struc:"uint8,sizeof=Payload"`
+	Payload []byte
+	CRC     uint32 `struc:"uint32,checksum=crc32,range=Magic:Payload"`
+	Trailer uint8
+}
+
+func TestChecksumPackUnpack(t *testing.T) {
+	in := &checksumPacket{Magic: 0xabcd, Payload: []byte("hello"), Trailer: 0xff}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	want := crc32.ChecksumIEEE(data[:8])
+	if in.CRC != want {
+		t.Fatalf("crc not written back: got %#x, want %#x", in.CRC, want)
+	}
+	if got := uint32(data[8])<<24 | uint32(data[9])<<16 | uint32(data[10])<<8 | uint32(data[11]); got != want {
+		t.Fatalf("packed crc: got %#x, want %#x", got, want)
+	}
+
+	out := &checksumPacket{}
+	if err := Unpack(bytes.NewReader(data), out); err != nil {
+		t.Fatal(err)
+	}
+	if string(out.Payload) != "hello" || out.CRC != want || out.Trailer != 0xff {
+		t.Fatalf("unpack: got %+v", out)
+	}
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[4] ^= 0x01
+	err := Unpack(bytes.NewReader(corrupt), &checksumPacket{})
+	if !IsChecksumMismatch(err) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if !IsChecksumMismatch(fmt.Errorf("read packet: %w", err)) {
+		t.Fatalf("wrapped checksum mismatch not detected: %v", err)
+	}
+	var structErr *Error
+	if !errors.As(err, &structErr) || structErr.Context["field"] != "CRC" || structErr.Context["algorithm"] != "crc32" {
+		t.Fatalf("unexpected error context: %v", err)
+	}
+}
+
+// checksumIPv4 的校验和字段位于覆盖范围内部，计算时按 0 处理
+type checksumIPv4 struct {
+	VersionIHL  uint8
+	TOS         uint8
+	TotalLength uint16
+	ID          uint16
+	Flags       uint16
+	TTL         uint8
+	Protocol    uint8
+	Checksum    uint16 `struc:"uint16,checksum=inet,range=VersionIHL:Dst"`
+	Src         [4]byte
+	Dst         [4]byte
+}
+
+func TestChecksumInsideRange(t *testing.T) {
+	in := &checksumIPv4{
+		VersionIHL: 0x45, TotalLength: 0x73, Flags: 0x4000, TTL: 0x40, Protocol: 0x11,
+		Src: [4]byte{192, 168, 0, 1}, Dst: [4]byte{192, 168, 0, 199},
+	}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if in.Checksum != 0xb861 {
+		t.Fatalf("inet checksum: got %#x, want 0xb861", in.Checksum)
+	}
+
+	out := &checksumIPv4{}
+	if err := Unpack(bytes.NewReader(buf.Bytes()), out); err != nil {
+		t.Fatal(err)
+	}
+	if *out != *in {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+type checksumAligned struct {
+	A   uint8
+	CRC uint32 `struc:"uint32,checksum=crc32,range=A,align=4"`
+}
+
+type checksumLayout struct {
+	A   uint8
+	CRC uint32 `struc:"uint32,checksum=crc32,range=A"`
+	B   uint16
+}
+
+func TestChecksumAfterPadding(t *testing.T) {
+	// 校验和写在对齐填充之后的字段位置上，覆盖范围不含填充
+	aligned := &checksumAligned{A: 7}
+	var buf bytes.Buffer
+	if err := Pack(&buf, aligned); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if want := crc32.ChecksumIEEE([]byte{7}); aligned.CRC != want || binary.BigEndian.Uint32(data[4:]) != want {
+		t.Fatalf("align: got % x (crc %#x), want crc %#x at offset 4", data, aligned.CRC, want)
+	}
+	alignedOut := &checksumAligned{}
+	if err := Unpack(bytes.NewReader(data), alignedOut); err != nil {
+		t.Fatal(err)
+	}
+	if *alignedOut != *aligned {
+		t.Fatalf("align round trip: got %+v, want %+v", alignedOut, aligned)
+	}
+
+	options := LayoutOptions(LayoutAMD64)
+	layout := &checksumLayout{A: 7, B: 0x0102}
+	buf.Reset()
+	if err := PackWithOptions(&buf, layout, options); err != nil {
+		t.Fatal(err)
+	}
+	data = buf.Bytes()
+	if want := crc32.ChecksumIEEE([]byte{7}); layout.CRC != want || binary.LittleEndian.Uint32(data[4:]) != want {
+		t.Fatalf("layout: got % x (crc %#x), want crc %#x at offset 4", data, layout.CRC, want)
+	}
+	layoutOut := &checksumLayout{}
+	if err := UnpackWithOptions(bytes.NewReader(data), layoutOut, options); err != nil {
+		t.Fatal(err)
+	}
+	if *layoutOut != *layout {
+		t.Fatalf("layout round trip: got %+v, want %+v", layoutOut, layout)
+	}
+}
+
+// checksumBitfields 的覆盖范围从位域组中间开始或结束，按整个存储单元计算
+type checksumBitfields struct {
+	A     uint8 `struc:"uint8,bits=4"`
+	B     uint8 `struc:"uint8,bits=4"`
+	C     uint8
+	Sum   uint8 `struc:"uint8,checksum=sum8,range=B:C"`
+	First uint8 `struc:"uint8,checksum=sum8,range=A:A"`
+}
+
+func TestChecksumBitfields(t *testing.T) {
+	in := &checksumBitfields{A: 1, B: 2, C: 3}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x12, 0x03, 0x15, 0x12}; !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got % x, want % x", buf.Bytes(), want)
+	}
+	out := &checksumBitfields{}
+	if err := Unpack(bytes.NewReader(buf.Bytes()), out); err != nil {
+		t.Fatal(err)
+	}
+	if *out != *in {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestRegisterChecksum(t *testing.T) {
+	if err := RegisterChecksum("crc32", func([]byte) uint64 { return 0 }); err == nil {
+		t.Fatal("expected duplicate registration error")
+	}
+	if err := RegisterChecksum("checksum-test-len", func(data []byte) uint64 { return uint64(len(data)) }); err != nil {
+		t.Fatal(err)
+	}
+
+	in := &struct {
+		Data [3]byte
+		Sum  uint8 `struc:"uint8,checksum=checksum-test-len"`
+	}{Data: [3]byte{1, 2, 3}}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{1, 2, 3, 3}) {
+		t.Fatalf("custom checksum: got %x", buf.Bytes())
+	}
+}
+
+func TestChecksumTagErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"unknown algorithm", &struct {
+			A   uint8
+			Sum uint8 `struc:"uint8,checksum=md5"`
+		}{}, "unknown checksum"},
+		{"bad range", &struct {
+			A   uint8
+			Sum uint8 `struc:"uint8,checksum=sum8,range=A:Missing"`
+		}{}, "does not exist"},
+		{"not integer", &struct {
+			A   uint8
+			Sum float32 `struc:"float32,checksum=crc32"`
+		}{}, "fixed-size integer"},
+		{"range only", &struct {
+			A uint8 `struc:"uint8,range=A"`
+		}{}, "requires `checksum=`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sizeof(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package struc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// complexPartType 返回复数类型实部和虚部各自对应的浮点类型
+func complexPartType(t Type) Type {
+	if t == Complex64 {
+		return Float32
+	}
+	return Float64
+}
+
+// writeComplex 将复数写入缓冲区，先实部后虚部，两部分各自遵循字节序
+func (f *Field) writeComplex(buffer []byte, value complex128, resolvedType Type, byteOrder binary.ByteOrder) error {
+	partType := complexPartType(resolvedType)
+	if err := f.writeFloat(buffer, real(value), partType, byteOrder); err != nil {
+		return err
+	}
+	return f.writeFloat(buffer[partType.Size():], imag(value), partType, byteOrder)
+}
+
+// readComplex 从缓冲区读取复数
+func (f *Field) readComplex(buffer []byte, resolvedType Type, byteOrder binary.ByteOrder) complex128 {
+	if resolvedType == Complex64 {
+		return complex(float64(unsafeGetFloat32(buffer, byteOrder)), float64(unsafeGetFloat32(buffer[4:], byteOrder)))
+	}
+	return complex(unsafeGetFloat64(buffer, byteOrder), unsafeGetFloat64(buffer[8:], byteOrder))
+}
+
+// complexKindMatches 判断 Go 复数类型的内存布局是否与线上格式一致
+// 一致时小端序切片可以直接整块拷贝
+func complexKindMatches(kind reflect.Kind, resolvedType Type) bool {
+	return (kind == reflect.Complex64 && resolvedType == Complex64) ||
+		(kind == reflect.Complex128 && resolvedType == Complex128)
+}
+
+// packComplex 打包复数字段，支持单个值、切片和数组
+func (f *Field) packComplex(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, options *Options) (int, error) {
+	byteOrder := f.determineByteOrder(options)
+	elementSize := resolvedType.Size()
+
+	if !f.IsSlice {
+		if f.IsPointer {
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.Kind() != reflect.Complex64 && fieldValue.Kind() != reflect.Complex128 {
+			return 0, fmt.Errorf("struc: cannot pack %v as %s (field `%s`)", fieldValue.Type(), resolvedType, f.Name)
+		}
+		if err := f.writeComplex(buffer, fieldValue.Complex(), resolvedType, byteOrder); err != nil {
+			return 0, err
+		}
+		return elementSize, nil
+	}
+
+	totalSize := length * elementSize
+	dataLength := fieldValue.Len()
+	if dataLength > length {
+		dataLength = length
+	}
+
+	// 小端序且内存布局一致时直接拷贝底层数组
+	if byteOrder == binary.LittleEndian && complexKindMatches(f.kind, resolvedType) {
+		if ptr, ok := sliceDataPointer(fieldValue); ok && dataLength > 0 {
+			copy(buffer, unsafe.Slice((*byte)(ptr), dataLength*elementSize))
+			if dataLength < length {
+				memclr(buffer[dataLength*elementSize : totalSize])
+			}
+			return totalSize, nil
+		}
+	}
+
+	for i := 0; i < length; i++ {
+		var value complex128
+		if i < dataLength {
+			elem := fieldValue.Index(i)
+			if elem.Kind() != reflect.Complex64 && elem.Kind() != reflect.Complex128 {
+				return 0, fmt.Errorf("struc: cannot pack %v as %s (field `%s`)", elem.Type(), resolvedType, f.Name)
+			}
+			value = elem.Complex()
+		}
+		if err := f.writeComplex(buffer[i*elementSize:], value, resolvedType, byteOrder); err != nil {
+			return 0, fmt.Errorf("failed to pack slice element %d: %w", i, err)
+		}
+	}
+	return totalSize, nil
+}
+
+// unpackComplex 解包复数字段
+func (f *Field) unpackComplex(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, options *Options) error {
+	byteOrder := f.determineByteOrder(options)
+	elementSize := resolvedType.Size()
+
+	if !f.IsSlice {
+		if f.IsPointer {
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.Kind() != reflect.Complex64 && fieldValue.Kind() != reflect.Complex128 {
+			return fmt.Errorf("struc: refusing to unpack complex into field %s of type %s", f.Name, fieldValue.Type())
+		}
+		fieldValue.SetComplex(f.readComplex(buffer, resolvedType, byteOrder))
+		return nil
+	}
+
+	if !f.IsArray {
+		if fieldValue.Cap() < length {
+			fieldValue.Set(reflect.MakeSlice(fieldValue.Type(), length, length))
+		} else if fieldValue.Len() != length {
+			fieldValue.Set(fieldValue.Slice(0, length))
+		}
+	}
+	if length > fieldValue.Len() {
+		length = fieldValue.Len()
+	}
+	if f.kind != reflect.Complex64 && f.kind != reflect.Complex128 {
+		return fmt.Errorf("struc: refusing to unpack complex into field %s of type %s", f.Name, fieldValue.Type())
+	}
+
+	if byteOrder == binary.LittleEndian && complexKindMatches(f.kind, resolvedType) {
+		if ptr, ok := sliceDataPointer(fieldValue); ok && length > 0 {
+			copy(unsafe.Slice((*byte)(ptr), length*elementSize), buffer)
+			return nil
+		}
+	}
+
+	for i := 0; i < length; i++ {
+		fieldValue.Index(i).SetComplex(f.readComplex(buffer[i*elementSize:], resolvedType, byteOrder))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package struc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+type complexExample struct {
+	C64  complex64
+	C128 complex128  `struc:"little"`
+	Down complex128  `struc:"complex64"`
+	IQ   []complex64 `struc:"[2]complex64,little"`
+	Arr  [2]complex128
+}
+
+func TestComplexEncoding(t *testing.T) {
+	in := &complexExample{
+		C64:  complex(1, -2),
+		C128: complex(0.5, 3),
+		Down: complex(1.5, 2.5),
+		IQ:   []complex64{complex(1, 2), complex(-1, -2)},
+		Arr:  [2]complex128{complex(4, 5), complex(6, 7)},
+	}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 8+16+8+16+32 {
+		t.Fatalf("complex pack: got %d bytes", buf.Len())
+	}
+	raw := buf.Bytes()
+	if got := binary.BigEndian.Uint32(raw[4:]); got != 0xc0000000 {
+		t.Fatalf("complex64 imaginary part: got %#x", got)
+	}
+	if got := binary.LittleEndian.Uint64(raw[16:]); got != 0x4008000000000000 {
+		t.Fatalf("complex128 little endian imaginary part: got %#x", got)
+	}
+	if got := binary.LittleEndian.Uint32(raw[44:]); got != 0xc0000000 {
+		t.Fatalf("complex64 slice element: got %#x", got)
+	}
+
+	out := &complexExample{}
+	if err := Unpack(bytes.NewReader(raw), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("complex round trip: got %+v, want %+v", out, in)
+	}
+
+	format, err := GetFormatString(&complexExample{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ">ff<dd>ff<ffff>dddd"; format != want {
+		t.Fatalf("complex format: got %q, want %q", format, want)
+	}
+}
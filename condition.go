@@ -0,0 +1,40 @@
+package struc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// handleIfTag 编译字段的 if= 条件表达式
+// 条件只能引用位于该字段之前的字段，保证解包时这些字段已经读取
+func handleIfTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField) error {
+	if fieldTag.If == "" {
+		return nil
+	}
+	if fieldDesc.BitSize > 0 {
+		return fmt.Errorf("struc: bitfield `%s` cannot be conditional", field.Name)
+	}
+
+	condition, err := parseFieldExpr(fieldTag.If)
+	if err != nil {
+		return fmt.Errorf("%w (field `%s`)", err, field.Name)
+	}
+	if err := condition.resolveFields(structType, field.Index[0]); err != nil {
+		return fmt.Errorf("%w (field `%s`)", err, field.Name)
+	}
+	fieldDesc.condition = condition
+	return nil
+}
+
+// present 判断字段在当前结构体值下是否存在
+// 没有条件的字段总是存在；条件值非零时字段存在
+func (f *Field) present(structValue reflect.Value) (bool, error) {
+	if f.condition == nil {
+		return true, nil
+	}
+	value, err := f.condition.eval(structValue)
+	if err != nil {
+		return false, fmt.Errorf("%w (field `%s`)", err, f.Name)
+	}
+	return value != 0, nil
+}
@@ -0,0 +1,106 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type conditionFrame struct {
+	Version uint8
+	Flags   uint8
+	Session uint32 `struc:"uint32,if=Flags&0x04"`
+	Ext     uint16 `struc:"uint16,if=Version>=2 && (Flags&0x01) == 0"`
+	Name    string `struc:"[4]byte,if=!(Flags&0x80)"`
+	Tail    uint8
+}
+
+func TestConditionalFields(t *testing.T) {
+	tests := []struct {
+		name string
+		in   conditionFrame
+		want []byte
+	}{
+		{
+			name: "all present",
+			in:   conditionFrame{Version: 2, Flags: 0x04, Session: 0x01020304, Ext: 0xabcd, Name: "test", Tail: 9},
+			want: []byte{2, 0x04, 1, 2, 3, 4, 0xab, 0xcd, 't', 'e', 's', 't', 9},
+		},
+		{
+			name: "all absent",
+			in:   conditionFrame{Version: 1, Flags: 0x81, Tail: 9},
+			want: []byte{1, 0x81, 9},
+		},
+		{
+			name: "version gate only",
+			in:   conditionFrame{Version: 3, Flags: 0x80, Ext: 7, Tail: 1},
+			want: []byte{3, 0x80, 0, 7, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Pack(&buf, &tt.in); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Fatalf("pack: got %x, want %x", buf.Bytes(), tt.want)
+			}
+			if size, err := Sizeof(&tt.in); err != nil || size != len(tt.want) {
+				t.Fatalf("sizeof: got %d (%v), want %d", size, err, len(tt.want))
+			}
+
+			// 预先填充的值在字段缺失时应被清零
+			out := conditionFrame{Session: 0xffff, Ext: 0xffff, Name: "junk"}
+			if err := Unpack(bytes.NewReader(tt.want), &out); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.in, out) {
+				t.Fatalf("round trip: got %+v, want %+v", out, tt.in)
+			}
+		})
+	}
+}
+
+func TestConditionalFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"unknown field", &struct {
+			A uint8 `struc:"uint8,if=Missing"`
+		}{}, "does not exist"},
+		{"later field", &struct {
+			A uint8 `struc:"uint8,if=B"`
+			B uint8
+		}{}, "must precede"},
+		{"syntax", &struct {
+			A uint8
+			B uint8 `struc:"uint8,if=(A&1"`
+		}{}, "missing `)`"},
+		{"bitfield", &struct {
+			A uint8
+			B uint8 `struc:"uint8,bits=4,if=A"`
+		}{}, "cannot be conditional"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sizeof(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	err := Pack(&buf, &struct {
+		A uint8
+		B uint8 `struc:"uint8,if=1/A"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "division by zero") {
+		t.Fatalf("expected division by zero error, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package struc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// handleConstTag 解析 const= / magic= 标签的常量值
+// 数值字段按 Go 整数/浮点字面量解析（支持 0x、0o、0b 前缀）；
+// string、[]byte 和 [N]byte 字段按 Go 转义字符串解析（如 \x89PNG），0x 开头时按十六进制字节解析。
+// 未指定长度的 string/[]byte 字段以常量长度作为字段长度
+func handleConstTag(fieldDesc *Field, fieldTag *strucTag, field reflect.StructField) error {
+	if !fieldTag.HasConst {
+		return nil
+	}
+	if fieldDesc.Sizeof != nil || fieldDesc.Sizefrom != nil || fieldDesc.Prefix != Invalid ||
+		fieldDesc.isOptional() || fieldDesc.condition != nil || fieldDesc.IsPointer {
+		return fmt.Errorf("struc: const field `%s` must have a fixed size and always be present", field.Name)
+	}
+	switch fieldDesc.Type {
+	case Struct, CustomType, Union, CString, Pad:
+		return fmt.Errorf("struc: const is not supported for %s field `%s`", fieldDesc.Type, field.Name)
+	}
+
+	constValue := reflect.New(field.Type).Elem()
+	switch {
+	case field.Type.Kind() == reflect.String || isByteSequence(field.Type):
+		raw, err := parseConstBytes(fieldTag.Const)
+		if err != nil {
+			return fmt.Errorf("struc: invalid const `%s` (field `%s`): %v", fieldTag.Const, field.Name, err)
+		}
+		if err := fieldDesc.setConstLength(len(raw), field); err != nil {
+			return err
+		}
+		switch field.Type.Kind() {
+		case reflect.String:
+			constValue.SetString(string(raw))
+		case reflect.Array:
+			reflect.Copy(constValue, reflect.ValueOf(raw))
+		default:
+			constValue.Set(reflect.ValueOf(raw).Convert(field.Type))
+		}
+	case fieldDesc.IsSlice:
+		return fmt.Errorf("struc: const is not supported for slice field `%s`", field.Name)
+	default:
+		if err := parseConstNumber(constValue, fieldTag.Const); err != nil {
+			return fmt.Errorf("struc: invalid const `%s` (field `%s`): %v", fieldTag.Const, field.Name, err)
+		}
+	}
+
+	fieldDesc.constValue = constValue
+	return nil
+}
+
+// isByteSequence 判断是否为 []byte 或 [N]byte 类型
+func isByteSequence(goType reflect.Type) bool {
+	kind := goType.Kind()
+	return (kind == reflect.Slice || kind == reflect.Array) && goType.Elem().Kind() == reflect.Uint8
+}
+
+// setConstLength 使字段长度与常量字节数一致
+// 显式声明了长度的字段要求长度完全相同
+func (f *Field) setConstLength(length int, field reflect.StructField) error {
+	if f.IsSlice && f.Length > 0 {
+		if f.Length != length {
+			return fmt.Errorf("struc: const of %d bytes does not match length %d of field `%s`", length, f.Length, field.Name)
+		}
+		return nil
+	}
+	f.Length = length
+	return nil
+}
+
+// parseConstBytes 解析字节常量：0x 开头为十六进制，否则按 Go 转义字符串处理
+func parseConstBytes(value string) ([]byte, error) {
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		return hex.DecodeString(value[2:])
+	}
+	unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(value, `"`, `\"`) + `"`)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(unquoted), nil
+}
+
+// parseConstNumber 按字段的 Go 类型解析数值常量
+func parseConstNumber(target reflect.Value, value string) error {
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			return err
+		}
+		if target.OverflowInt(n) {
+			return fmt.Errorf("value overflows %v", target.Type())
+		}
+		target.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 0, 64)
+		if err != nil {
+			return err
+		}
+		if target.OverflowUint(n) {
+			return fmt.Errorf("value overflows %v", target.Type())
+		}
+		target.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		target.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		target.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %v", target.Type())
+	}
+	return nil
+}
+
+// applyConst 打包前将常量写入字段，与 sizeof 字段一样直接更新结构体中的值
+func (f *Field) applyConst(fieldValue reflect.Value) {
+	if f.constMatches(fieldValue) {
+		return
+	}
+	if fieldValue.Kind() == reflect.Slice {
+		// 复制一份，避免结构体与缓存的常量共享底层数组
+		fieldValue.Set(reflect.AppendSlice(reflect.MakeSlice(fieldValue.Type(), 0, f.constValue.Len()), f.constValue))
+		return
+	}
+	fieldValue.Set(f.constValue)
+}
+
+// constMatches 判断字段值是否等于常量
+func (f *Field) constMatches(fieldValue reflect.Value) bool {
+	if fieldValue.Kind() == reflect.Slice {
+		return bytes.Equal(fieldValue.Bytes(), f.constValue.Bytes())
+	}
+	return fieldValue.Interface() == f.constValue.Interface()
+}
+
+// checkConst 校验解包得到的值是否等于常量
+// 不一致时返回 ErrConstMismatch，Context 中包含字段名以及期望和实际的编码字节
+func (f *Field) checkConst(fieldValue reflect.Value, options *Options) error {
+	if f.constMatches(fieldValue) {
+		return nil
+	}
+	expected := f.encodeValue(f.constValue, options)
+	actual := f.encodeValue(fieldValue, options)
+	return NewError(ErrConstMismatch,
+		fmt.Sprintf("field `%s` mismatch: expected %x, got %x", f.Name, expected, actual),
+		map[string]interface{}{"field": f.Name, "expected": expected, "actual": actual})
+}
+
+// encodeValue 按字段定义编码单个值，用于错误信息
+func (f *Field) encodeValue(value reflect.Value, options *Options) []byte {
+	addressable := reflect.New(value.Type()).Elem()
+	addressable.Set(value)
+
+	length := f.Length
+	if f.IsSlice && length <= 0 {
+		length = addressable.Len()
+	}
+	// 位域不单独占用字节，按存储单元的宽度输出位域自身的值
+	if f.BitSize > 0 {
+		buffer := make([]byte, f.bitUnit.Size())
+		value := f.getIntegerValue(addressable) & f.bitfieldMask()
+		if err := f.writeInteger(buffer, value, f.bitUnit, f.determineByteOrder(options)); err != nil {
+			return nil
+		}
+		return buffer
+	}
+	buffer := make([]byte, f.Size(addressable, options))
+	n, err := f.Pack(buffer, addressable, length, options)
+	if err != nil {
+		return nil
+	}
+	return buffer[:n]
+}
@@ -0,0 +1,126 @@
+package struc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type constHeader struct {
+	Magic   []byte  `struc:"magic=\\x89PNG\\r\\n\\x1a\\n"`
+	Class   [4]byte `struc:"magic=0xcafebabe"`
+	Name    string  `struc:"const=ELF"`
+	Version uint16  `struc:"uint16,const=0x0102"`
+	Major   uint8   `struc:"uint8,bits=4,const=3"`
+	Minor   uint8   `struc:"uint8,bits=4"`
+	Length  int32
+}
+
+func TestConstFields(t *testing.T) {
+	in := &constHeader{Minor: 5, Length: 7}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n',
+		0xca, 0xfe, 0xba, 0xbe,
+		'E', 'L', 'F',
+		0x01, 0x02,
+		0x35,
+		0, 0, 0, 7,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("const pack: got %x, want %x", buf.Bytes(), want)
+	}
+	if in.Version != 0x0102 || in.Name != "ELF" || in.Major != 3 {
+		t.Fatalf("const values not written back: %+v", in)
+	}
+
+	out := &constHeader{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Minor != 5 || out.Length != 7 || string(out.Magic) != "\x89PNG\r\n\x1a\n" {
+		t.Fatalf("const unpack: got %+v", out)
+	}
+}
+
+func TestConstMismatch(t *testing.T) {
+	valid := []byte{
+		0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n',
+		0xca, 0xfe, 0xba, 0xbe,
+		'E', 'L', 'F',
+		0x01, 0x02,
+		0x35,
+		0, 0, 0, 7,
+	}
+	tests := []struct {
+		name     string
+		offset   int
+		value    byte
+		field    string
+		expected []byte
+		actual   []byte
+	}{
+		{"magic bytes", 1, 'X', "Magic", []byte("\x89PNG\r\n\x1a\n"), []byte("\x89XNG\r\n\x1a\n")},
+		{"integer", 16, 0x03, "Version", []byte{1, 2}, []byte{1, 3}},
+		{"bitfield", 17, 0x45, "Major", []byte{3}, []byte{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte(nil), valid...)
+			data[tt.offset] = tt.value
+			err := Unpack(bytes.NewReader(data), &constHeader{})
+			if !IsConstMismatch(err) {
+				t.Fatalf("expected const mismatch, got %v", err)
+			}
+			if !IsConstMismatch(fmt.Errorf("read header: %w", err)) {
+				t.Fatalf("wrapped const mismatch not detected: %v", err)
+			}
+			var structErr *Error
+			if !errors.As(err, &structErr) {
+				t.Fatalf("expected *Error, got %T", err)
+			}
+			if structErr.Context["field"] != tt.field {
+				t.Fatalf("field: got %v, want %s", structErr.Context["field"], tt.field)
+			}
+			if !bytes.Equal(structErr.Context["expected"].([]byte), tt.expected) ||
+				!bytes.Equal(structErr.Context["actual"].([]byte), tt.actual) {
+				t.Fatalf("bytes: got %x/%x, want %x/%x", structErr.Context["expected"], structErr.Context["actual"], tt.expected, tt.actual)
+			}
+		})
+	}
+}
+
+func TestConstTagErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"length mismatch", &struct {
+			M [3]byte `struc:"magic=ABCD"`
+		}{}, "does not match length"},
+		{"overflow", &struct {
+			V uint8 `struc:"uint8,const=256"`
+		}{}, "overflows"},
+		{"bad hex", &struct {
+			M []byte `struc:"magic=0xzz"`
+		}{}, "invalid const"},
+		{"sizeof", &struct {
+			N    uint8 `struc:"uint8,sizeof=Data,const=1"`
+			Data []byte
+		}{}, "fixed size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sizeof(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package struc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// C 字符串支持
+//
+// - cstring: 以 NUL 结尾的变长字符串，打包时写入内容和结束符，解包时读取到第一个 NUL 为止
+// - [N]cstring: 固定 N 字节、以 NUL 填充的字符串（对应 C 的 char name[N]），
+//   解包时在第一个 NUL 处截断，打包时写入结束符并用 NUL 填充剩余空间
+//
+// 字段的 Go 类型可以是 string 或 []byte。
+// 与 [N]byte 不同，内容放不下（包括结束符）时打包会返回错误，而不是静默截断。
+
+// maxCStringLength 限制无界 cstring 解包时读取的最大长度，避免恶意数据导致无限增长
+const maxCStringLength = 1 << 20
+
+// handleCStringTag 处理 cstring 类型字段
+// cstring 在标签中使用 [N] 语法表示固定宽度，而不是切片
+func handleCStringTag(fieldDesc *Field, fieldTag *strucTag, field reflect.StructField) error {
+	if fieldDesc.Type != CString {
+		return nil
+	}
+
+	fieldType := field.Type
+	if fieldType.Kind() != reflect.String &&
+		!(fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Uint8) {
+		return fmt.Errorf("struc: cstring field `%s` must be a string or []byte", field.Name)
+	}
+	if fieldDesc.Sizefrom != nil {
+		return fmt.Errorf("struc: cstring field `%s` does not support sizeof/sizefrom", field.Name)
+	}
+
+	width := 0
+	if matches := arrayLengthParseRegex.FindStringSubmatch(fieldTag.Type); len(matches) > 1 && matches[1] != "" {
+		if fieldDesc.Length <= 0 {
+			return fmt.Errorf("struc: cstring field `%s` must have a positive width", field.Name)
+		}
+		width = fieldDesc.Length
+	}
+
+	fieldDesc.IsSlice = false
+	fieldDesc.IsArray = false
+	fieldDesc.Length = width
+	return nil
+}
+
+// cstringBytes 返回 cstring 字段的内容字节（不包含结束符）
+func cstringBytes(fieldValue reflect.Value) []byte {
+	if fieldValue.Kind() == reflect.String {
+		return unsafeString2Bytes(fieldValue.String())
+	}
+	return fieldValue.Bytes()
+}
+
+// calculateCStringSize 计算 cstring 字段的字节大小
+// 固定宽度返回宽度，无界字符串返回内容长度加结束符
+func (f *Field) calculateCStringSize(fieldValue reflect.Value) int {
+	if f.Length > 0 {
+		return f.Length
+	}
+	if f.IsPointer {
+		fieldValue = fieldValue.Elem()
+	}
+	return len(cstringBytes(fieldValue)) + 1
+}
+
+// packCString 打包 cstring 字段
+func (f *Field) packCString(buffer []byte, fieldValue reflect.Value) (int, error) {
+	if f.IsPointer {
+		fieldValue = fieldValue.Elem()
+	}
+	data := cstringBytes(fieldValue)
+	if bytes.IndexByte(data, 0) >= 0 {
+		return 0, fmt.Errorf("struc: cstring field %s contains a NUL byte", f.Name)
+	}
+
+	if f.Length == 0 {
+		n := copy(buffer, data)
+		buffer[n] = 0
+		return n + 1, nil
+	}
+
+	if len(data) >= f.Length {
+		return 0, fmt.Errorf("struc: cstring field %s value of %d bytes does not fit in %d bytes with terminator", f.Name, len(data), f.Length)
+	}
+	n := copy(buffer[:f.Length], data)
+	memclr(buffer[n:f.Length])
+	return f.Length, nil
+}
+
+// unpackCString 从读取器中解包 cstring 字段
+func (f *Field) unpackCString(reader io.Reader, fieldValue reflect.Value, scratch *scratchArena) error {
+	if f.IsPointer {
+		fieldValue = fieldValue.Elem()
+	}
+
+	if f.Length > 0 {
+		// 固定宽度：与 string/[]byte 字段一致，零拷贝借用共享缓冲区
+		buffer := unpackBasicTypeSlicePool.GetSlice(f.Length)
+		if _, err := io.ReadFull(reader, buffer); err != nil {
+			return err
+		}
+		f.setCStringValue(fieldValue, buffer)
+		return nil
+	}
+
+	data, err := readUntilNUL(reader, scratch)
+	if err != nil {
+		return err
+	}
+	if fieldValue.Kind() == reflect.String {
+		fieldValue.SetString(string(data))
+	} else {
+		fieldValue.SetBytes(data)
+	}
+	return nil
+}
+
+// unpackCStringBuffer 从缓冲区中解包 cstring 字段
+func (f *Field) unpackCStringBuffer(buffer []byte, fieldValue reflect.Value) error {
+	if f.IsPointer {
+		fieldValue = fieldValue.Elem()
+	}
+	f.setCStringValue(fieldValue, buffer)
+	return nil
+}
+
+// setCStringValue 将缓冲区中第一个 NUL 之前的内容设置到字段
+func (f *Field) setCStringValue(fieldValue reflect.Value, buffer []byte) {
+	length := bytes.IndexByte(buffer, 0)
+	if length < 0 {
+		length = len(buffer)
+	}
+	if fieldValue.Kind() == reflect.String {
+		unsafeSetString(fieldValue, buffer, length)
+		return
+	}
+	fieldValue.SetBytes(buffer[:length:length])
+}
+
+// readUntilNUL 逐字节读取直到遇到 NUL 结束符，返回不包含结束符的内容
+func readUntilNUL(reader io.Reader, scratch *scratchArena) ([]byte, error) {
+	source := newByteSource(reader, scratch)
+
+	data := []byte{}
+	for len(data) <= maxCStringLength {
+		b, err := source.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if b == 0 {
+			return data, nil
+		}
+		data = append(data, b)
+	}
+	return nil, fmt.Errorf("struc: cstring exceeds %d bytes without terminator", maxCStringLength)
+}
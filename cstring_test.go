@@ -0,0 +1,79 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+type cstringExample struct {
+	Name  string `struc:"[8]cstring"`
+	Label []byte `struc:"[4]cstring"`
+	Path  string `struc:"cstring"`
+	Tag   []byte `struc:"cstring"`
+	Tail  uint8
+}
+
+func TestCString(t *testing.T) {
+	var buf bytes.Buffer
+	in := &cstringExample{Name: "eth0", Label: []byte("ab"), Path: "/dev/null", Tag: []byte{}, Tail: 9}
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte("eth0\x00\x00\x00\x00ab\x00\x00/dev/null\x00\x00\x09")
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("cstring pack: got %q, want %q", buf.Bytes(), want)
+	}
+	if size, err := Sizeof(in); err != nil || size != len(want) {
+		t.Fatalf("cstring sizeof: got %d (%v), want %d", size, err, len(want))
+	}
+
+	out := &cstringExample{}
+	if err := Unpack(iotest.OneByteReader(bytes.NewReader(want)), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("cstring round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestCStringGarbageAfterTerminator(t *testing.T) {
+	data := []byte("ab\x00zzzzz" + "x\x00yy" + "p\x00" + "\x00" + "\x01")
+	out := &cstringExample{}
+	if err := Unpack(bytes.NewReader(data), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "ab" || string(out.Label) != "x" || out.Path != "p" {
+		t.Fatalf("cstring unpack did not stop at NUL: %+v", out)
+	}
+}
+
+type cstringFixed struct {
+	Name string `struc:"[4]cstring"`
+}
+
+func TestCStringErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Pack(&buf, &cstringFixed{Name: "abcd"}); err == nil {
+		t.Fatal("failed to error on cstring overflow")
+	}
+	if err := Pack(&buf, &cstringFixed{Name: "a\x00b"}); err == nil {
+		t.Fatal("failed to error on cstring with embedded NUL")
+	}
+	if err := Unpack(bytes.NewReader([]byte("abc")), &struct {
+		S string `struc:"cstring"`
+	}{}); err == nil {
+		t.Fatal("failed to error on unterminated cstring")
+	}
+}
+
+func TestCStringFormatString(t *testing.T) {
+	format, err := GetFormatString(&cstringFixed{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != ">4s" {
+		t.Fatalf("cstring format: got %q, want %q", format, ">4s")
+	}
+}
@@ -0,0 +1,71 @@
+package struc
+
+import (
+	"io"
+	"strconv"
+)
+
+// FP8E4M3 表示一个 OCP FP8 E4M3 浮点数, 内部使用 float64 存储
+//
+// 格式:
+// 1 位: 符号位
+// 4 位: 指数位 (偏移值为7)
+// 3 位: 小数位 (隐含前导1)
+//
+// 没有无穷大，指数和尾数全为 1 表示 NaN，最大有限值为 448；
+// 打包时溢出和无穷大都变为 NaN
+type FP8E4M3 float64
+
+// Pack 将 FP8E4M3 值序列化为1字节
+func (f *FP8E4M3) Pack(buffer []byte, options *Options) (int, error) {
+	return packMinifloatValue(buffer, float64(*f), FP8E4M3Type, options)
+}
+
+// Unpack 从1字节反序列化 FP8E4M3 值
+func (f *FP8E4M3) Unpack(reader io.Reader, length int, options *Options) error {
+	value, err := unpackMinifloatValue(reader, FP8E4M3Type, options)
+	*f = FP8E4M3(value)
+	return err
+}
+
+// Size 返回 FP8E4M3 的字节大小, 固定为1
+func (f *FP8E4M3) Size(options *Options) int {
+	return 1
+}
+
+// String 返回 FP8E4M3 值的字符串表示
+func (f *FP8E4M3) String() string {
+	return strconv.FormatFloat(float64(*f), 'g', -1, 32)
+}
+
+// FP8E5M2 表示一个 OCP FP8 E5M2 浮点数, 内部使用 float64 存储
+//
+// 格式:
+// 1 位: 符号位
+// 5 位: 指数位 (偏移值为15)
+// 2 位: 小数位 (隐含前导1)
+//
+// 与 IEEE 754 规则一致，支持次正规数、无穷大和 NaN，最大有限值为 57344
+type FP8E5M2 float64
+
+// Pack 将 FP8E5M2 值序列化为1字节
+func (f *FP8E5M2) Pack(buffer []byte, options *Options) (int, error) {
+	return packMinifloatValue(buffer, float64(*f), FP8E5M2Type, options)
+}
+
+// Unpack 从1字节反序列化 FP8E5M2 值
+func (f *FP8E5M2) Unpack(reader io.Reader, length int, options *Options) error {
+	value, err := unpackMinifloatValue(reader, FP8E5M2Type, options)
+	*f = FP8E5M2(value)
+	return err
+}
+
+// Size 返回 FP8E5M2 的字节大小, 固定为1
+func (f *FP8E5M2) Size(options *Options) int {
+	return 1
+}
+
+// String 返回 FP8E5M2 值的字符串表示
+func (f *FP8E5M2) String() string {
+	return strconv.FormatFloat(float64(*f), 'g', -1, 32)
+}
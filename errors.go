@@ -1,6 +1,7 @@
 package struc
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -45,20 +46,31 @@ const (
 
 	// ErrUnpackingFailed 解包失败错误
 	ErrUnpackingFailed
+
+	// ErrUnknownDiscriminator 联合字段的判别值未注册错误
+	ErrUnknownDiscriminator
+
+	// ErrConstMismatch 常量/魔数字段校验失败错误
+	ErrConstMismatch
+	// ErrChecksumMismatch 校验和字段校验失败错误
+	ErrChecksumMismatch
 )
 
 // errorMessages 定义了错误代码对应的错误消息
 var errorMessages = map[ErrorCode]string{
-	ErrInvalidType:      "invalid type",
-	ErrBufferTooSmall:   "buffer too small",
-	ErrUnsupportedType:  "unsupported type",
-	ErrInvalidOptions:   "invalid options",
-	ErrFieldMismatch:    "field mismatch",
-	ErrCustomTypeFailed: "custom type operation failed",
-	ErrTypeRegistration: "type registration failed",
-	ErrSizeCalculation:  "size calculation failed",
-	ErrPackingFailed:    "packing failed",
-	ErrUnpackingFailed:  "unpacking failed",
+	ErrInvalidType:          "invalid type",
+	ErrBufferTooSmall:       "buffer too small",
+	ErrUnsupportedType:      "unsupported type",
+	ErrInvalidOptions:       "invalid options",
+	ErrFieldMismatch:        "field mismatch",
+	ErrCustomTypeFailed:     "custom type operation failed",
+	ErrTypeRegistration:     "type registration failed",
+	ErrSizeCalculation:      "size calculation failed",
+	ErrPackingFailed:        "packing failed",
+	ErrUnpackingFailed:      "unpacking failed",
+	ErrUnknownDiscriminator: "unknown union discriminator",
+	ErrConstMismatch:        "constant field mismatch",
+	ErrChecksumMismatch:     "checksum mismatch",
 }
 
 // NewError 创建一个新的错误
@@ -203,6 +215,33 @@ func IsCustomTypeFailed(err error) bool {
 	return false
 }
 
+// IsUnknownDiscriminator 检查是否为联合字段判别值未注册错误，支持被包装的错误
+func IsUnknownDiscriminator(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code == ErrUnknownDiscriminator
+	}
+	return false
+}
+
+// IsConstMismatch 检查是否为常量/魔数字段校验失败错误，支持被包装的错误
+func IsConstMismatch(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code == ErrConstMismatch
+	}
+	return false
+}
+
+// IsChecksumMismatch 检查是否为校验和字段校验失败错误，支持被包装的错误
+func IsChecksumMismatch(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code == ErrChecksumMismatch
+	}
+	return false
+}
+
 // ==================== 错误包装函数 ====================
 
 // WrapError 包装现有错误为 struc 错误
@@ -0,0 +1,379 @@
+package struc
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fieldExpr 是标签中引用其他字段的整数表达式，例如 if=Flags&0x04
+// 表达式在解析结构体时编译一次，字段名在此时解析为字段索引
+//
+// 支持的运算符（优先级与 Go 相同，从高到低）：
+//
+//	一元:  - ! ^ ~
+//	5:     * / % << >> &
+//	4:     + - | ^
+//	3:     == != < <= > >=
+//	2:     &&
+//	1:     ||
+type fieldExpr struct {
+	src  string       // 表达式原文
+	root exprNode     // 语法树根节点
+	refs []*exprField // 表达式中引用的字段
+}
+
+// exprEnv 是表达式求值时的上下文
+type exprEnv struct {
+	structValue reflect.Value // 字段所在的结构体
+	x           int64         // expr= 表达式中变量 x 的值
+	exact       bool          // 除法不能整除时是否报错
+}
+
+// exprNode 是表达式语法树的节点
+type exprNode interface {
+	eval(env exprEnv) (int64, error)
+}
+
+// exprConst 整数常量
+type exprConst struct {
+	value int64
+}
+
+// exprField 对同一结构体中其他字段的引用
+type exprField struct {
+	name  string
+	index []int
+}
+
+// exprVar expr= 表达式中的变量 x
+type exprVar struct{}
+
+// exprUnary 一元运算
+type exprUnary struct {
+	op string
+	x  exprNode
+}
+
+// exprBinary 二元运算
+type exprBinary struct {
+	op   string
+	x, y exprNode
+}
+
+func (e *exprConst) eval(exprEnv) (int64, error) {
+	return e.value, nil
+}
+
+func (e *exprVar) eval(env exprEnv) (int64, error) {
+	return env.x, nil
+}
+
+func (e *exprField) eval(env exprEnv) (int64, error) {
+	value := env.structValue.FieldByIndex(e.index)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(value.Uint()), nil
+	case reflect.Bool:
+		return boolToInt64(value.Bool()), nil
+	default:
+		return 0, fmt.Errorf("struc: expression field `%s` is not an integer", e.name)
+	}
+}
+
+func (e *exprUnary) eval(env exprEnv) (int64, error) {
+	x, err := e.x.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	switch e.op {
+	case "-":
+		return -x, nil
+	case "!":
+		return boolToInt64(x == 0), nil
+	default: // "^", "~"
+		return ^x, nil
+	}
+}
+
+func (e *exprBinary) eval(env exprEnv) (int64, error) {
+	x, err := e.x.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	// 逻辑运算短路求值
+	switch e.op {
+	case "&&":
+		if x == 0 {
+			return 0, nil
+		}
+	case "||":
+		if x != 0 {
+			return 1, nil
+		}
+	}
+	y, err := e.y.eval(env)
+	if err != nil {
+		return 0, err
+	}
+
+	switch e.op {
+	case "*":
+		return x * y, nil
+	case "/", "%":
+		if y == 0 {
+			return 0, fmt.Errorf("struc: division by zero in expression")
+		}
+		if e.op == "/" {
+			if env.exact && x%y != 0 {
+				return 0, fmt.Errorf("struc: %d/%d is not integral", x, y)
+			}
+			return x / y, nil
+		}
+		return x % y, nil
+	case "<<", ">>":
+		if y < 0 || y > 63 {
+			return 0, fmt.Errorf("struc: invalid shift count %d in expression", y)
+		}
+		if e.op == "<<" {
+			return x << uint(y), nil
+		}
+		return x >> uint(y), nil
+	case "&":
+		return x & y, nil
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "|":
+		return x | y, nil
+	case "^":
+		return x ^ y, nil
+	case "==":
+		return boolToInt64(x == y), nil
+	case "!=":
+		return boolToInt64(x != y), nil
+	case "<":
+		return boolToInt64(x < y), nil
+	case "<=":
+		return boolToInt64(x <= y), nil
+	case ">":
+		return boolToInt64(x > y), nil
+	case ">=":
+		return boolToInt64(x >= y), nil
+	default: // "&&", "||"
+		return boolToInt64(y != 0), nil
+	}
+}
+
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// eval 对结构体当前值求表达式
+func (e *fieldExpr) eval(structValue reflect.Value) (int64, error) {
+	return e.root.eval(exprEnv{structValue: structValue})
+}
+
+// evalWith 以变量 x 的值求表达式，除法必须整除
+func (e *fieldExpr) evalWith(structValue reflect.Value, x int64) (int64, error) {
+	return e.root.eval(exprEnv{structValue: structValue, x: x, exact: true})
+}
+
+// String 返回表达式原文
+func (e *fieldExpr) String() string {
+	return e.src
+}
+
+// exprBinaryPrecedence 定义二元运算符的优先级
+var exprBinaryPrecedence = map[string]int{
+	"*": 5, "/": 5, "%": 5, "<<": 5, ">>": 5, "&": 5,
+	"+": 4, "-": 4, "|": 4, "^": 4,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"&&": 2,
+	"||": 1,
+}
+
+// exprOperators 按长度降序排列，保证优先匹配双字符运算符
+var exprOperators = []string{
+	"<<", ">>", "==", "!=", "<=", ">=", "&&", "||",
+	"*", "/", "%", "&", "+", "-", "|", "^", "<", ">", "!", "~", "(", ")",
+}
+
+// exprParser 是递归下降的表达式解析器
+type exprParser struct {
+	src      string
+	tokens   []string
+	pos      int
+	refs     []*exprField
+	allowVar bool // 是否允许变量 x
+}
+
+// parseFieldExpr 编译表达式，字段引用需要随后通过 resolveFields 解析
+func parseFieldExpr(src string) (*fieldExpr, error) {
+	return parseExpr(src, false)
+}
+
+// parseVarExpr 编译可以使用变量 x 的表达式（用于 expr= 标签）
+func parseVarExpr(src string) (*fieldExpr, error) {
+	return parseExpr(src, true)
+}
+
+// parseExpr 编译表达式
+func parseExpr(src string, allowVar bool) (*fieldExpr, error) {
+	tokens, err := tokenizeExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("struc: empty expression")
+	}
+
+	p := &exprParser{src: src, tokens: tokens, allowVar: allowVar}
+	root, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("struc: unexpected `%s` in expression `%s`", p.tokens[p.pos], src)
+	}
+	return &fieldExpr{src: src, root: root, refs: p.refs}, nil
+}
+
+// tokenizeExpr 将表达式拆分为数字、标识符和运算符
+func tokenizeExpr(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case isExprIdentChar(c):
+			start := i
+			for i < len(src) && isExprIdentChar(src[i]) {
+				i++
+			}
+			tokens = append(tokens, src[start:i])
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, op)
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("struc: invalid character %q in expression `%s`", c, src)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+func isExprIdentChar(c byte) bool {
+	return c == '_' || c == '.' ||
+		(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// parseBinary 按优先级爬升解析二元表达式
+func (p *exprParser) parseBinary(minPrecedence int) (exprNode, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		precedence, ok := exprBinaryPrecedence[op]
+		if !ok || precedence < minPrecedence {
+			return x, nil
+		}
+		p.pos++
+		y, err := p.parseBinary(precedence + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &exprBinary{op: op, x: x, y: y}
+	}
+}
+
+// parseUnary 解析一元运算、括号、常量和字段引用
+func (p *exprParser) parseUnary() (exprNode, error) {
+	token := p.peek()
+	if token == "" {
+		return nil, fmt.Errorf("struc: unexpected end of expression `%s`", p.src)
+	}
+	p.pos++
+
+	switch token {
+	case "-", "!", "^", "~":
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprUnary{op: token, x: x}, nil
+	case "(":
+		x, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("struc: missing `)` in expression `%s`", p.src)
+		}
+		p.pos++
+		return x, nil
+	}
+
+	if c := token[0]; c >= '0' && c <= '9' {
+		value, err := strconv.ParseInt(token, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("struc: invalid number `%s` in expression `%s`", token, p.src)
+		}
+		return &exprConst{value: value}, nil
+	}
+	if token == "x" && p.allowVar {
+		return &exprVar{}, nil
+	}
+	if isExprIdentChar(token[0]) {
+		ref := &exprField{name: token}
+		p.refs = append(p.refs, ref)
+		return ref, nil
+	}
+	return nil, fmt.Errorf("struc: unexpected `%s` in expression `%s`", token, p.src)
+}
+
+// resolveFields 将表达式中的字段名解析为字段索引
+// 被引用的字段必须是整数或布尔字段，并且位于 before 索引之前（解包时已读取）
+func (e *fieldExpr) resolveFields(structType reflect.Type, before int) error {
+	for _, ref := range e.refs {
+		structField, ok := structType.FieldByName(ref.name)
+		if !ok {
+			return fmt.Errorf("struc: field `%s` in expression `%s` does not exist", ref.name, e.src)
+		}
+		if structField.Index[0] >= before {
+			return fmt.Errorf("struc: field `%s` in expression `%s` must precede the field using it", ref.name, e.src)
+		}
+		switch structField.Type.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Bool:
+		default:
+			return fmt.Errorf("struc: field `%s` in expression `%s` is not an integer", ref.name, e.src)
+		}
+		ref.index = structField.Index
+	}
+	return nil
+}
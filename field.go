@@ -14,19 +14,50 @@ import (
 // Field 表示结构体中的单个字段
 // 包含了字段的所有元数据信息，用于二进制打包和解包
 type Field struct {
-	Name       string           // 字段名称
-	IsPointer  bool             // 字段是否为指针类型
-	Index      int              // 字段在结构体中的索引
-	Type       Type             // 字段的二进制类型
-	defType    Type             // 默认的二进制类型
-	IsArray    bool             // 字段是否为数组
-	IsSlice    bool             // 字段是否为切片
-	Length     int              // 数组/固定切片的长度
-	ByteOrder  binary.ByteOrder // 字段的字节序
-	Sizeof     []int            // sizeof 引用的字段索引
-	Sizefrom   []int            // 大小引用的字段索引
-	NestFields Fields           // 嵌套结构体的字段
-	kind       reflect.Kind     // Go 的反射类型
+	Name          string           // 字段名称
+	IsPointer     bool             // 字段是否为指针类型
+	Index         int              // 字段在结构体中的索引
+	Type          Type             // 字段的二进制类型
+	defType       Type             // 默认的二进制类型
+	IsArray       bool             // 字段是否为数组
+	IsSlice       bool             // 字段是否为切片
+	Length        int              // 数组/固定切片的长度
+	ByteOrder     binary.ByteOrder // 字段的字节序
+	Sizeof        []int            // sizeof 引用的字段索引
+	Sizefrom      []int            // 大小引用的字段索引
+	Bytesof       []int            // 字节长度引用的字段索引
+	NestFields    Fields           // 嵌套结构体的字段
+	BitSize       int              // 位域宽度（位数），0 表示非位域字段
+	Prefix        Type             // 内联长度前缀的类型，Invalid 表示无前缀
+	Switch        []int            // 联合字段的判别字段索引
+	Optional      []int            // 可选字段的存在标记字段索引
+	Presence      Type             // 可选字段内联存在标记的类型，Invalid 表示无内联标记
+	Offsetfrom    []int            // 按偏移存放时保存偏移的字段索引
+	Rest          bool             // 是否读取剩余的全部输入
+	Align         int              // 字段起始偏移的对齐字节数，0 表示不对齐
+	kind          reflect.Kind     // Go 的反射类型
+	bitOffset     int              // 位域在存储单元中的起始位（从最低位计）
+	bitUnit       Type             // 位域所在存储单元的类型
+	bitLSB        bool             // 位域是否从最低位开始排列
+	bitFirst      bool             // 是否为存储单元中的第一个位域
+	bitLast       bool             // 是否为存储单元中的最后一个位域
+	switchFor     []int            // 作为判别字段时所属联合字段的索引
+	condition     *fieldExpr       // if= 条件表达式，nil 表示字段总是存在
+	optionalFor   []int            // 作为存在标记字段时所属可选字段的索引
+	constValue    reflect.Value    // const= 声明的常量值，无效值表示非常量字段
+	checksum      *checksumSpec    // checksum= 声明的校验和算法及覆盖范围
+	offsetFor     []int            // 作为偏移字段时所属数据字段的索引
+	until         *untilSpec       // until= 声明的终止元素，nil 表示非终止切片
+	byteLength    bool             // sizefrom 引用的长度是否为字节数
+	lengthExpr    *fieldExpr       // sizefrom= 表达式，如 IHL*4-20
+	inverseExpr   *fieldExpr       // expr= 表达式，打包时由长度 x 计算长度字段的值
+	recordSize    int              // _ 标记字段声明的定长记录字节数
+	orderDeclared bool             // 字节序是否由标签或结构体声明（而非默认的大端序）
+	sizeofUp      int              // sizeof 引用的字段所在的外层结构体层数，0 表示当前结构体
+	sizefromUp    int              // sizefrom 引用的字段所在的外层结构体层数，0 表示当前结构体
+	scoped        bool             // 嵌套结构体是否需要外层结构体的值
+	scale         *scaleSpec       // scale=/offset=/q 格式声明的缩放参数，nil 表示非缩放字段
+	numeric       *numericSpec     // BCD 和 ASCII 数字字段的格式，宽度保存在 Length 中
 }
 
 // ==================== 基础工具函数 ====================
@@ -38,6 +69,9 @@ func (f *Field) String() string {
 		return "{type: invalid, len: 0}"
 	}
 
+	if f.recordSize > 0 {
+		return fmt.Sprintf("{record size: %d}", f.recordSize)
+	}
 	if f.Type == Pad {
 		return fmt.Sprintf("{type: %s, len: %d}", f.Type, f.Length)
 	}
@@ -51,7 +85,9 @@ func (f *Field) String() string {
 	if f.ByteOrder != nil {
 		fmt.Fprintf(buffer, ", order: %v", f.ByteOrder)
 	}
-	if f.Sizefrom != nil {
+	if f.lengthExpr != nil {
+		fmt.Fprintf(buffer, ", sizefrom: %s", f.lengthExpr)
+	} else if f.Sizefrom != nil {
 		fmt.Fprintf(buffer, ", sizefrom: %v", f.Sizefrom)
 	} else if f.Length > 0 {
 		fmt.Fprintf(buffer, ", len: %d", f.Length)
@@ -59,17 +95,72 @@ func (f *Field) String() string {
 	if f.Sizeof != nil {
 		fmt.Fprintf(buffer, ", sizeof: %v", f.Sizeof)
 	}
+	if f.Bytesof != nil {
+		fmt.Fprintf(buffer, ", bytesof: %v", f.Bytesof)
+	}
+	if f.inverseExpr != nil {
+		fmt.Fprintf(buffer, ", expr: %s", f.inverseExpr)
+	}
+	if f.byteLength {
+		buffer.WriteString(", bytes")
+	}
+	if f.BitSize > 0 {
+		fmt.Fprintf(buffer, ", bits: %d", f.BitSize)
+	}
+	if f.Prefix != Invalid {
+		fmt.Fprintf(buffer, ", prefix: %s", f.Prefix)
+	}
+	if f.Switch != nil {
+		fmt.Fprintf(buffer, ", switch: %v", f.Switch)
+	}
+	if f.condition != nil {
+		fmt.Fprintf(buffer, ", if: %s", f.condition)
+	}
+	if f.Optional != nil {
+		fmt.Fprintf(buffer, ", optional: %v", f.Optional)
+	}
+	if f.Presence != Invalid {
+		fmt.Fprintf(buffer, ", presence: %s", f.Presence)
+	}
+	if f.constValue.IsValid() {
+		fmt.Fprintf(buffer, ", const: %v", f.constValue)
+	}
+	if f.Offsetfrom != nil {
+		fmt.Fprintf(buffer, ", offsetfrom: %v", f.Offsetfrom)
+	}
+	if f.Rest {
+		buffer.WriteString(", rest")
+	}
+	if f.Align > 1 {
+		fmt.Fprintf(buffer, ", align: %d", f.Align)
+	}
+	if f.until != nil {
+		fmt.Fprintf(buffer, ", until: %v", f.until.terminator)
+	}
+	if f.scale != nil {
+		fmt.Fprintf(buffer, ", scale: %g, offset: %g", f.scale.scale, f.scale.offset)
+	}
+	if f.numeric != nil && f.numeric.left {
+		buffer.WriteString(", left")
+	}
+	if f.checksum != nil {
+		fmt.Fprintf(buffer, ", checksum: %s", f.checksum.algorithm)
+	}
 	buffer.WriteString("}")
 
 	return buffer.String()
 }
 
 // determineByteOrder 返回要使用的字节序
-// 优先使用选项中指定的字节序，否则使用字段自身的字节序
+// OrderForce 模式下优先使用选项中指定的字节序；OrderDefault 模式下选项只作用于未声明字节序的字段
+// Options.Layout 的字节序与 OrderDefault 相同，只作用于未声明字节序的字段
 func (f *Field) determineByteOrder(options *Options) binary.ByteOrder {
-	if options.Order != nil {
+	if options.Order != nil && (options.OrderMode == OrderForce || !f.orderDeclared) {
 		return options.Order
 	}
+	if !f.orderDeclared && options.Layout != nil {
+		return options.Layout.Order
+	}
 	return f.ByteOrder
 }
 
@@ -94,6 +185,10 @@ func (f *Field) getIntegerValue(fieldValue reflect.Value) uint64 {
 // Size 计算字段在二进制格式中占用的字节数
 // 考虑了对齐和填充要求
 func (f *Field) Size(fieldValue reflect.Value, options *Options) int {
+	if f.BitSize > 0 {
+		return f.alignSize(f.bitfieldSize(), options)
+	}
+
 	resolvedType := resolveTypeForOptions(f.Type, options)
 	totalSize := 0
 
@@ -104,10 +199,25 @@ func (f *Field) Size(fieldValue reflect.Value, options *Options) int {
 		totalSize = f.Length
 	case CustomType:
 		totalSize = f.calculateCustomSize(fieldValue, options)
+	case Uvarint, Varint, Sleb128:
+		totalSize = f.calculateVarintSize(fieldValue, resolvedType)
+	case CString:
+		totalSize = f.calculateCStringSize(fieldValue)
+	case Union:
+		totalSize = unionSize(fieldValue, options)
+	case BCD, Octal, Decimal, HexASCII:
+		totalSize = f.Length
 	default:
 		totalSize = f.calculateBasicSize(fieldValue, resolvedType, options)
 	}
 
+	if f.Prefix != Invalid {
+		totalSize += f.prefixSize(fieldValue.Len())
+	}
+	if f.until != nil {
+		totalSize += f.untilSize(options)
+	}
+
 	return f.alignSize(totalSize, options)
 }
 
@@ -125,6 +235,10 @@ func (f *Field) calculateStructSize(fieldValue reflect.Value, options *Options)
 		}
 		return totalSize
 	}
+	// 非可选的 nil 指针按零值计算大小，不解引用 nil，由 Pack 报告错误
+	if fieldValue.Kind() == reflect.Ptr && fieldValue.IsNil() {
+		fieldValue = reflect.New(fieldValue.Type().Elem())
+	}
 	return f.NestFields.Sizeof(fieldValue, options)
 }
 
@@ -167,9 +281,41 @@ func (f *Field) alignSize(size int, options *Options) int {
 // Pack 将字段值打包到缓冲区中
 // 处理所有类型的字段，包括填充、切片和单个值
 func (f *Field) Pack(buffer []byte, fieldValue reflect.Value, length int, options *Options) (int, error) {
-	if resolvedType := resolveTypeForOptions(f.Type, options); resolvedType == Pad {
+	if f.BitSize > 0 {
+		return f.packBitfield(buffer, fieldValue, options)
+	}
+	if f.Prefix != Invalid {
+		return f.packPrefixed(buffer, fieldValue, options)
+	}
+	if f.until != nil {
+		return f.packUntil(buffer, fieldValue, options)
+	}
+
+	resolvedType := resolveTypeForOptions(f.Type, options)
+	if resolvedType == Pad {
 		return f.packPaddingBytes(buffer, length)
 	}
+	if f.scale != nil {
+		return f.packScaled(buffer, fieldValue, length, resolvedType, options)
+	}
+	if resolvedType.IsVarint() {
+		return f.packVarint(buffer, fieldValue, length, resolvedType)
+	}
+	if resolvedType == CString {
+		return f.packCString(buffer, fieldValue)
+	}
+	switch resolvedType {
+	case Union:
+		return f.packUnion(buffer, fieldValue, options)
+	case Int128Type, Uint128Type:
+		return f.packInt128(buffer, fieldValue, length, resolvedType, options)
+	case Complex64, Complex128:
+		return f.packComplex(buffer, fieldValue, length, resolvedType, options)
+	case Unix32, Unix64, UnixMs64, NTP64, Filetime, DOSDateTime, GPSWeek:
+		return f.packTime(buffer, fieldValue, length, resolvedType, options)
+	case BCD, Octal, Decimal, HexASCII:
+		return f.packNumeric(buffer, fieldValue, resolvedType)
+	}
 
 	if f.IsSlice {
 		return f.packSliceValue(buffer, fieldValue, length, options)
@@ -206,6 +352,14 @@ func (f *Field) packSingleValue(buffer []byte, fieldValue reflect.Value, length
 			return elementSize, nil
 		}
 	}
+	if resolvedType.IsOddWidthInteger() {
+		putUintN(buffer, f.getIntegerValue(fieldValue), resolvedType.Size(), byteOrder)
+		return resolvedType.Size(), nil
+	}
+	if resolvedType.IsMinifloat() {
+		writeMinifloat(buffer, fieldValue.Float(), resolvedType, byteOrder)
+		return resolvedType.Size(), nil
+	}
 
 	switch resolvedType {
 	case Struct:
@@ -260,6 +414,12 @@ func (f *Field) packSliceValue(buffer []byte, fieldValue reflect.Value, length i
 		return 0, nil
 	}
 	byteOrder := f.determineByteOrder(options)
+	if resolvedType.IsOddWidthInteger() {
+		return f.packOddWidthSlice(buffer, fieldValue, length, resolvedType, byteOrder), nil
+	}
+	if resolvedType.IsMinifloat() {
+		return f.packMinifloatSlice(buffer, fieldValue, length, resolvedType, byteOrder), nil
+	}
 	elementSize := resolvedType.Size()
 	dataLength := fieldValue.Len()
 	totalSize := length * elementSize
@@ -368,6 +528,8 @@ func (f *Field) writeInteger(buffer []byte, intValue uint64, resolvedType Type,
 		unsafePutUint32(buffer, uint32(intValue), byteOrder)
 	case Int64, Uint64:
 		unsafePutUint64(buffer, intValue, byteOrder)
+	case Int24, Uint24, Int40, Uint40, Int48, Uint48, Int56, Uint56:
+		putUintN(buffer, intValue, resolvedType.Size(), byteOrder)
 	default:
 		return fmt.Errorf("unsupported integer type: %v", resolvedType)
 	}
@@ -396,6 +558,26 @@ func (f *Field) writeFloat(buffer []byte, floatValue float64, resolvedType Type,
 func (f *Field) Unpack(buffer []byte, fieldValue reflect.Value, length int, options *Options) error {
 	resolvedType := resolveTypeForOptions(f.Type, options)
 
+	if resolvedType.IsVarint() {
+		return f.unpackVarintBuffer(buffer, fieldValue, length, resolvedType)
+	}
+	if f.scale != nil {
+		return f.unpackScaled(buffer, fieldValue, length, resolvedType, options)
+	}
+	if resolvedType == CString {
+		return f.unpackCStringBuffer(buffer, fieldValue)
+	}
+	switch resolvedType {
+	case Int128Type, Uint128Type:
+		return f.unpackInt128(buffer, fieldValue, length, resolvedType, options)
+	case Complex64, Complex128:
+		return f.unpackComplex(buffer, fieldValue, length, resolvedType, options)
+	case Unix32, Unix64, UnixMs64, NTP64, Filetime, DOSDateTime, GPSWeek:
+		return f.unpackTime(buffer, fieldValue, length, resolvedType, options)
+	case BCD, Octal, Decimal, HexASCII:
+		return f.unpackNumeric(buffer, fieldValue, resolvedType)
+	}
+
 	if resolvedType == Pad || f.kind == reflect.String {
 		return f.unpackPaddingOrStringValue(buffer, fieldValue, resolvedType)
 	}
@@ -422,6 +604,14 @@ func (f *Field) unpackPaddingOrStringValue(buffer []byte, fieldValue reflect.Val
 func (f *Field) unpackSliceValue(buffer []byte, fieldValue reflect.Value, length int, options *Options) error {
 	resolvedType := resolveTypeForOptions(f.Type, options)
 	byteOrder := f.determineByteOrder(options)
+	if resolvedType.IsOddWidthInteger() {
+		f.unpackOddWidthSlice(buffer, fieldValue, length, resolvedType, byteOrder)
+		return nil
+	}
+	if resolvedType.IsMinifloat() {
+		f.unpackMinifloatSlice(buffer, fieldValue, length, resolvedType, byteOrder)
+		return nil
+	}
 
 	// 数组 [N]byte / [N]uint8：字节序无关，直接拷贝内存，避免逐元素 reflect。
 	if f.IsArray && resolvedType == Uint8 && fieldValue.Kind() == reflect.Array && fieldValue.CanAddr() {
@@ -480,8 +670,8 @@ func (f *Field) unpackSingleValue(buffer []byte, fieldValue reflect.Value, lengt
 
 	resolvedType := resolveTypeForOptions(f.Type, options)
 
-	// 优化: 对基本类型进行快速处理
-	if resolvedType.IsBasicType() {
+	// 优化: 对基本类型和非标准宽度整数进行快速处理
+	if resolvedType.IsBasicType() || resolvedType.IsOddWidthInteger() {
 		switch resolvedType {
 		case Float32, Float64:
 			// 处理浮点数类型
@@ -497,8 +687,9 @@ func (f *Field) unpackSingleValue(buffer []byte, fieldValue reflect.Value, lengt
 				return nil
 			}
 			return fmt.Errorf("struc: refusing to unpack float into field %s of type %s", f.Name, f.kind.String())
-		case Bool, Int8, Int16, Int32, Int64, Uint8, Uint16, Uint32, Uint64:
-			// 处理整数和布尔类型
+		case Bool, Int8, Int16, Int32, Int64, Uint8, Uint16, Uint32, Uint64,
+			Int24, Uint24, Int40, Uint40, Int48, Uint48, Int56, Uint56:
+			// 处理整数和布尔类型，包括非标准宽度整数
 			intValue := f.readInteger(buffer, resolvedType, byteOrder)
 			switch f.kind {
 			case reflect.Bool:
@@ -511,6 +702,10 @@ func (f *Field) unpackSingleValue(buffer []byte, fieldValue reflect.Value, lengt
 			return nil
 		}
 	}
+	if resolvedType.IsMinifloat() {
+		fieldValue.SetFloat(readMinifloat(buffer, resolvedType, byteOrder))
+		return nil
+	}
 
 	switch resolvedType {
 	case Struct:
@@ -552,6 +747,8 @@ func (f *Field) readInteger(buffer []byte, resolvedType Type, byteOrder binary.B
 		return uint64(unsafeGetUint32(buffer, byteOrder))
 	case Uint64:
 		return unsafeGetUint64(buffer, byteOrder)
+	case Int24, Uint24, Int40, Uint40, Int48, Uint48, Int56, Uint56:
+		return readOddWidthInteger(buffer, resolvedType, byteOrder)
 	default:
 		return 0
 	}
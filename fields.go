@@ -57,15 +57,38 @@ func (f Fields) Sizeof(structValue reflect.Value, options *Options) int {
 		structValue = structValue.Elem()
 	}
 
-	totalSize := 0
+	// 对齐填充取决于字段的位置，先计算按顺序存放的部分，再加上按偏移存放的数据
+	totalSize := f.fixedSizeof(structValue, options)
 	for i, field := range f {
-		if field != nil {
-			totalSize += field.Size(structValue.Field(i), options)
+		if field != nil && field.Offsetfrom != nil {
+			totalSize += f.fieldSizeof(structValue, i, field, options)
 		}
 	}
+	if size := f.recordSize(); size > totalSize {
+		totalSize = size
+	}
 	return totalSize
 }
 
+// sizeofLength 返回 sizeof 字段在打包时应写入的长度值
+// 联合字段没有元素个数的概念，按打包后的字节数计算
+func (f Fields) sizeofLength(structValue reflect.Value, field *Field, options *Options) int {
+	if field.Bytesof != nil {
+		return f.bytesofLength(structValue, field, options)
+	}
+	base := refBase(structValue, field.sizeofUp, options)
+	var target reflect.Value
+	if len(field.Sizeof) == 1 {
+		target = base.Field(field.Sizeof[0])
+	} else {
+		target = base.FieldByIndex(field.Sizeof)
+	}
+	if target.Kind() == reflect.Interface {
+		return unionSize(target, options)
+	}
+	return target.Len()
+}
+
 // sizefrom 根据引用字段的值确定切片或数组的长度
 // 支持有符号和无符号整数类型的长度字段
 func (f Fields) sizefrom(structValue reflect.Value, fieldIndex []int) int {
@@ -106,46 +129,148 @@ func (f Fields) Pack(buffer []byte, structValue reflect.Value, options *Options)
 
 	position := 0 // 当前缓冲区位置
 
+	// 按偏移存放的字段在布局阶段确定位置，并回填对应的偏移字段
+	hasOffsets := f.hasOffsets()
+	if hasOffsets {
+		if err := f.layoutOffsets(structValue, options); err != nil {
+			return 0, err
+		}
+	}
+
+	// 存在校验和字段时记录每个字段的起止偏移，写完所有字段后回填校验和
+	var spans []fieldSpan
+	if f.hasChecksums() {
+		spans = make([]fieldSpan, len(f))
+	}
+
 	for i, field := range f {
-		if field == nil {
+		if field == nil || field.Offsetfrom != nil {
+			if spans != nil {
+				spans[i] = fieldSpan{start: position, end: position}
+			}
 			continue
 		}
 
-		fieldValue := structValue.Field(i)
-		fieldLength := field.Length
+		padding, err := field.fieldPadding(structValue, position, options)
+		if err != nil {
+			return position, err
+		}
+		if padding > 0 {
+			clear(buffer[position : position+padding])
+			position += padding
+		}
 
-		if field.Sizefrom != nil {
-			fieldLength = f.sizefrom(structValue, field.Sizefrom)
+		bytesWritten, err := f.packField(buffer[position:], structValue, i, field, options)
+		if err != nil {
+			return position, err
 		}
-		if fieldLength <= 0 && field.IsSlice {
-			fieldLength = fieldValue.Len()
+		if spans != nil {
+			spans[i] = fieldSpan{start: position, end: position + bytesWritten}
 		}
+		position += bytesWritten
+	}
 
-		if field.Sizeof != nil {
-			var sizeofLength int
-			if len(field.Sizeof) == 1 {
-				sizeofLength = structValue.Field(field.Sizeof[0]).Len()
-			} else {
-				sizeofLength = structValue.FieldByIndex(field.Sizeof).Len()
-			}
+	// 使用 C 布局时固定部分以尾部填充结束
+	if padding := f.tailPadding(position, options); padding > 0 {
+		clear(buffer[position : position+padding])
+		position += padding
+	}
 
-			switch field.kind {
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				fieldValue.SetInt(int64(sizeofLength))
-			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				fieldValue.SetUint(uint64(sizeofLength))
-			default:
-				panic(fmt.Sprintf("sizeof field is not int or uint type: %s, %s", field.Name, fieldValue.Type()))
-			}
+	// 校验和只覆盖固定部分，按偏移存放的数据在校验和回填之前写入
+	if hasOffsets {
+		bytesWritten, err := f.packOutOfLine(buffer[position:], structValue, options)
+		if err != nil {
+			return position, err
+		}
+		position += bytesWritten
+	}
+
+	if spans != nil {
+		f.widenBitfieldSpans(spans)
+		if err := f.fillChecksums(buffer, spans, structValue, options); err != nil {
+			return position, err
+		}
+	}
+	return f.padRecord(buffer, position)
+}
+
+// packField 打包单个字段，返回写入的字节数（包括内联存在标记）
+func (f Fields) packField(buffer []byte, structValue reflect.Value, i int, field *Field, options *Options) (int, error) {
+	present, err := field.present(structValue)
+	if err != nil || !present {
+		return 0, err
+	}
+
+	fieldValue := structValue.Field(i)
+	fieldLength := field.Length
+
+	if field.Sizefrom != nil && !field.byteLength {
+		if fieldLength, err = f.lengthFrom(structValue, field, options); err != nil {
+			return 0, err
 		}
+	}
+	if fieldLength <= 0 && field.IsSlice {
+		fieldLength = fieldValue.Len()
+	}
 
-		bytesWritten, err := field.Pack(buffer[position:], fieldValue, fieldLength, options)
+	if field.Sizeof != nil || field.Bytesof != nil {
+		sizeofLength, err := f.sizeofValue(structValue, field, options)
 		if err != nil {
-			return bytesWritten, err
+			return 0, err
 		}
-		position += bytesWritten
+
+		switch field.kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fieldValue.SetInt(sizeofLength)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fieldValue.SetUint(uint64(sizeofLength))
+		default:
+			panic(fmt.Sprintf("sizeof field is not int or uint type: %s, %s", field.Name, fieldValue.Type()))
+		}
+	}
+	if field.switchFor != nil {
+		if err := f.setDiscriminator(structValue, field, fieldValue); err != nil {
+			return 0, err
+		}
+	}
+	if field.optionalFor != nil {
+		f.setPresenceFlag(structValue, fieldValue, field)
+	}
+	if field.constValue.IsValid() {
+		field.applyConst(fieldValue)
+	}
+
+	position := 0
+	if field.isOptional() {
+		isPresent := !fieldValue.IsNil()
+		if field.Presence != Invalid {
+			markerSize, err := field.packPresence(buffer, isPresent, options)
+			if err != nil {
+				return 0, err
+			}
+			position += markerSize
+		}
+		if !isPresent {
+			return position, nil
+		}
+	} else if field.isNilPointer(fieldValue) {
+		return 0, fmt.Errorf("struc: cannot pack nil pointer field `%s` (tag it `optional` to omit it)", field.Name)
+	}
+
+	if field.scoped {
+		options = options.enter(structValue)
+	}
+	bytesWritten, err := field.Pack(buffer[position:], fieldValue, fieldLength, options)
+	if err != nil {
+		return position, err
 	}
-	return position, nil
+	return position + bytesWritten, nil
+}
+
+// isNilPointer 判断字段是否为无法打包的 nil 指针
+// *big.Int 是 128 位整数自身的载体，nil 按 0 处理
+func (f *Field) isNilPointer(fieldValue reflect.Value) bool {
+	return f.IsPointer && fieldValue.Kind() == reflect.Ptr && fieldValue.IsNil() && fieldValue.Type() != bigIntPtrType
 }
 
 // Release 释放 Fields 切片中的所有 Field 对象
@@ -219,6 +344,12 @@ func (f Fields) unpackBasicType(reader io.Reader, fieldValue reflect.Value, fiel
 	if resolvedType == CustomType {
 		return fieldValue.Addr().Interface().(CustomBinaryer).Unpack(reader, fieldLength, options)
 	}
+	if resolvedType.IsVarint() {
+		return field.unpackVarint(reader, fieldValue, fieldLength, resolvedType, scratch)
+	}
+	if resolvedType == CString {
+		return field.unpackCString(reader, fieldValue, scratch)
+	}
 
 	dataSize := fieldLength * resolvedType.Size()
 
@@ -250,31 +381,181 @@ func (f Fields) unpackWithScratch(reader io.Reader, structValue reflect.Value, o
 		structValue = structValue.Elem()
 	}
 
+	var bitUnit uint64 // 当前位域存储单元的值
+
+	// 按偏移存放的字段在顺序部分解包完成后通过随机访问读取
+	var source offsetSource
+	var base int64
+	if f.hasOffsets() {
+		var err error
+		if source, base, err = offsetSourceOf(reader); err != nil {
+			return err
+		}
+	}
+
+	// 存在校验和字段时记录读取的原始字节及每个字段的起止偏移，解包完成后校验
+	var recorder *checksumRecorder
+	var spans []fieldSpan
+	if f.hasChecksums() {
+		recorder = &checksumRecorder{reader: reader}
+		reader = recorder
+		spans = make([]fieldSpan, len(f))
+	}
+
+	// 存在对齐字段或定长记录时记录结构体内的读取位置
+	var tracker *positionReader
+	if f.needsPosition(options) {
+		tracker = &positionReader{reader: reader}
+		reader = tracker
+	}
+
 	for i, field := range f {
-		if field == nil {
+		if recorder != nil {
+			spans[i] = fieldSpan{start: len(recorder.data), end: len(recorder.data)}
+		}
+		if field == nil || field.Offsetfrom != nil {
 			continue
 		}
 
 		fieldValue := structValue.Field(i)
-		fieldLength := field.Length
-		if field.Sizefrom != nil {
-			fieldLength = f.sizefrom(structValue, field.Sizefrom)
+
+		present, err := field.present(structValue)
+		if err != nil {
+			return err
+		}
+		if !present {
+			// 缺失的字段重置为零值，避免保留上一次解包的内容
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			continue
 		}
 
-		if fieldValue.Kind() == reflect.Ptr && !fieldValue.Elem().IsValid() {
-			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		if tracker != nil {
+			if padding := field.alignPadding(tracker.position, options); padding > 0 {
+				if _, err := io.CopyN(io.Discard, reader, int64(padding)); err != nil {
+					return err
+				}
+			}
+		}
+		if recorder != nil {
+			spans[i].start = len(recorder.data)
 		}
 
-		if field.Type == Struct {
-			if err := f.unpackStruct(reader, fieldValue, field, fieldLength, options, scratch); err != nil {
-				return err
+		if field.BitSize > 0 {
+			if field.bitFirst {
+				buffer := scratch.Get(field.bitUnit.Size())
+				if _, err := io.ReadFull(reader, buffer); err != nil {
+					return err
+				}
+				bitUnit = field.readInteger(buffer, field.bitUnit, field.determineByteOrder(options))
 			}
-		} else {
-			if err := f.unpackBasicType(reader, fieldValue, field, fieldLength, options, scratch); err != nil {
+			field.unpackBitfield(bitUnit, fieldValue)
+			if field.constValue.IsValid() {
+				if err := field.checkConst(fieldValue, options); err != nil {
+					return err
+				}
+			}
+			if recorder != nil {
+				spans[i].end = len(recorder.data)
+			}
+			continue
+		}
+
+		if err := f.unpackField(reader, structValue, fieldValue, field, options, scratch); err != nil {
+			return err
+		}
+		if recorder != nil {
+			spans[i].end = len(recorder.data)
+		}
+	}
+
+	if tracker != nil {
+		if padding := f.tailPadding(tracker.position, options); padding > 0 {
+			if _, err := io.CopyN(io.Discard, reader, int64(padding)); err != nil {
 				return err
 			}
 		}
 	}
+
+	// 校验和只覆盖固定部分
+	if source != nil {
+		if err := f.unpackOutOfLine(source, base, structValue, options, scratch); err != nil {
+			return err
+		}
+	}
+	if tracker != nil {
+		if err := f.skipRecordPadding(reader, tracker.position, source, base); err != nil {
+			return err
+		}
+	}
+	if recorder != nil {
+		f.widenBitfieldSpans(spans)
+		return f.verifyChecksums(recorder.data, spans, structValue)
+	}
+	return nil
+}
+
+// unpackField 从 reader 中解包单个非位域字段
+func (f Fields) unpackField(reader io.Reader, structValue reflect.Value, fieldValue reflect.Value, field *Field, options *Options, scratch *scratchArena) error {
+	// 嵌套结构体使用带有外层结构体值的选项，当前字段的长度仍按当前结构体计算
+	nestedOptions := options
+	if field.scoped {
+		nestedOptions = options.enter(structValue)
+	}
+
+	if field.isOptional() {
+		isPresent, err := field.unpackPresence(reader, structValue, options, scratch)
+		if err != nil {
+			return err
+		}
+		if !isPresent {
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			return nil
+		}
+	}
+
+	if field.Rest {
+		return f.unpackRest(reader, fieldValue, field, nestedOptions, scratch)
+	}
+	if field.until != nil {
+		return f.unpackUntil(reader, fieldValue, field, nestedOptions, scratch)
+	}
+
+	fieldLength := field.Length
+	if field.Sizefrom != nil {
+		length, err := f.lengthFrom(structValue, field, options)
+		if err != nil {
+			return err
+		}
+		fieldLength = length
+	}
+	if field.Prefix != Invalid {
+		prefixLength, err := field.unpackPrefix(reader, options, scratch)
+		if err != nil {
+			return err
+		}
+		fieldLength = prefixLength
+	}
+
+	if fieldValue.Kind() == reflect.Ptr && !fieldValue.Elem().IsValid() {
+		fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+	}
+
+	if field.byteLength {
+		return f.unpackByteLength(reader, structValue, fieldValue, field, fieldLength, nestedOptions, scratch)
+	}
+
+	switch field.Type {
+	case Union:
+		return f.unpackUnion(reader, structValue, fieldValue, field, options, scratch)
+	case Struct:
+		return f.unpackStruct(reader, fieldValue, field, fieldLength, nestedOptions, scratch)
+	}
+	if err := f.unpackBasicType(reader, fieldValue, field, fieldLength, options, scratch); err != nil {
+		return err
+	}
+	if field.constValue.IsValid() {
+		return field.checkConst(fieldValue, options)
+	}
 	return nil
 }
 
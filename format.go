@@ -23,6 +23,37 @@ var formatMap = map[Type]string{
 	String:  "s", // char[]
 	Bool:    "?", // boolean
 	Pad:     "x", // padding
+
+	// struct 模块没有非 2 的幂宽度的整数，按对应宽度的字节串描述
+	Int24:  "3s",
+	Uint24: "3s",
+	Int40:  "5s",
+	Uint40: "5s",
+	Int48:  "6s",
+	Uint48: "6s",
+	Int56:  "7s",
+	Uint56: "7s",
+
+	// 128 位整数按 16 字节串描述，复数按实部、虚部两个浮点数描述
+	Int128Type:  "16s",
+	Uint128Type: "16s",
+	Complex64:   "ff",
+	Complex128:  "dd",
+
+	// 时间戳按线上的整数描述
+	Unix32:      "I",
+	Unix64:      "q",
+	UnixMs64:    "q",
+	NTP64:       "II",
+	Filetime:    "Q",
+	DOSDateTime: "I",
+	GPSWeek:     "HI",
+
+	// float16 对应 struct 模块的 e，其余小浮点数按原始字节描述
+	Float16Type:  "e",
+	BFloat16Type: "2s",
+	FP8E4M3Type:  "c",
+	FP8E5M2Type:  "c",
 }
 
 // GetFormatString 返回结构体的格式字符串，用于描述二进制数据的布局。
@@ -147,7 +178,8 @@ func (s *formatState) processField(field *Field) error {
 // handleEndianness 处理字段的字节序
 func (s *formatState) handleEndianness(field *Field, startPos int) error {
 	if field.ByteOrder == nil || field.ByteOrder == s.curOrder {
-		if s.isFirst {
+		// 前面的字段可能没有输出任何内容（如位域、sizefrom 字段），此时不能重复写入字节序标记
+		if s.isFirst && s.lastEndian < 0 && s.parentOrder != "" {
 			s.buffer.WriteString(s.parentOrder)
 			s.lastEndian = s.buffer.Len() - 1
 		}
@@ -155,9 +187,7 @@ func (s *formatState) handleEndianness(field *Field, startPos int) error {
 	}
 
 	// 需要切换字节序
-	if s.isFirst {
-		s.writeEndianness(field.ByteOrder)
-	} else if s.lastEndian >= 0 && startPos == s.lastEndian+1 {
+	if s.lastEndian >= 0 && startPos == s.lastEndian+1 {
 		// 如果上一个字节序标记后没有任何有效字符，直接替换
 		s.buffer.Truncate(s.lastEndian)
 		s.writeEndianness(field.ByteOrder)
@@ -186,12 +216,56 @@ func formatField(buf *bytes.Buffer, field *Field) error {
 		return formatFields(buf, field.NestFields, "", binary.BigEndian)
 	}
 
+	// 条件字段是否存在取决于运行时的值，无法用固定格式描述
+	if field.condition != nil || field.isOptional() {
+		return fmt.Errorf("field %s is conditional and has no fixed format", field.Name)
+	}
+	if field.Offsetfrom != nil {
+		return fmt.Errorf("field %s is stored at an offset and has no fixed format", field.Name)
+	}
+	if field.Align > 1 || field.recordSize > 0 {
+		return fmt.Errorf("field %s depends on its offset and has no fixed format", field.Name)
+	}
+
+	// 位域字段：整个存储单元只输出一次
+	if field.BitSize > 0 {
+		if field.bitLast {
+			buf.WriteString(formatMap[field.bitUnit])
+		}
+		return nil
+	}
+
 	if len(field.Sizeof) > 0 {
 		return formatSizeofField(buf, field)
 	}
 
-	// 跳过 sizefrom 字段
-	if len(field.Sizefrom) > 0 {
+	// 跳过 sizefrom 字段、读取剩余输入的字段和终止切片
+	if len(field.Sizefrom) > 0 || field.Rest || field.until != nil {
+		return nil
+	}
+
+	// 内联长度前缀字段：只输出前缀，内容长度可变
+	if field.Prefix != Invalid {
+		formatChar, ok := formatMap[field.Prefix]
+		if !ok {
+			return fmt.Errorf("unsupported prefix type for field %s: %v", field.Name, field.Prefix)
+		}
+		buf.WriteString(formatChar)
+		return nil
+	}
+
+	if field.Type == CString {
+		if field.Length <= 0 {
+			return fmt.Errorf("field `%s` is an unbounded cstring with no fixed width", field.Name)
+		}
+		writeInt(buf, field.Length)
+		buf.WriteString(formatMap[String])
+		return nil
+	}
+
+	if field.numeric != nil {
+		writeInt(buf, field.Length)
+		buf.WriteString(formatMap[String])
 		return nil
 	}
 
@@ -218,9 +292,10 @@ func formatArrayField(buf *bytes.Buffer, field *Field) error {
 		return fmt.Errorf("field `%s` is a slice with no length or sizeof field", field.Name)
 	}
 
-	baseType := field.defType
-	if baseType == 0 {
-		baseType = field.Type
+	// 优先使用标签声明的二进制类型，Go 字段类型仅用于识别字符串
+	baseType := field.Type
+	if field.defType == String {
+		baseType = String
 	}
 
 	if field.Type == Pad {
@@ -0,0 +1,268 @@
+package struc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// Uint128 表示一个 128 位无符号整数
+// Hi 为高 64 位，Lo 为低 64 位，与字节序无关
+type Uint128 struct {
+	Hi uint64
+	Lo uint64
+}
+
+// Big 将 Uint128 转换为 *big.Int
+func (u Uint128) Big() *big.Int {
+	var raw [16]byte
+	binary.BigEndian.PutUint64(raw[:8], u.Hi)
+	binary.BigEndian.PutUint64(raw[8:], u.Lo)
+	return new(big.Int).SetBytes(raw[:])
+}
+
+// String 返回 Uint128 的十进制表示
+func (u Uint128) String() string {
+	return u.Big().String()
+}
+
+var (
+	uint128GoType = reflect.TypeOf(Uint128{})
+	bigIntPtrType = reflect.TypeOf((*big.Int)(nil))
+
+	// twoTo128 用于在有符号 128 位整数与补码之间转换
+	twoTo128 = new(big.Int).Lsh(big.NewInt(1), 128)
+	// maxInt128 和 minInt128 为有符号 128 位整数的取值范围
+	maxInt128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	minInt128 = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+)
+
+// defaultInt128Type 返回未声明标签时 Go 类型对应的 128 位整数类型
+// Uint128 映射为 uint128，*big.Int 映射为 int128，同样适用于它们的切片和数组
+func defaultInt128Type(goType reflect.Type) (Type, bool) {
+	if kind := goType.Kind(); kind == reflect.Slice || kind == reflect.Array {
+		goType = goType.Elem()
+	}
+	switch goType {
+	case uint128GoType:
+		return Uint128Type, true
+	case bigIntPtrType:
+		return Int128Type, true
+	}
+	return Invalid, false
+}
+
+// isInt128Holder 判断 Go 类型能否作为单个 128 位整数的载体
+func isInt128Holder(goType reflect.Type) bool {
+	switch goType {
+	case uint128GoType, bigIntPtrType:
+		return true
+	}
+	switch goType.Kind() {
+	case reflect.Array:
+		return goType.Len() == 16 && goType.Elem().Kind() == reflect.Uint8
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// handleInt128Tag 校验 int128/uint128 字段的 Go 类型
+// [16]byte 作为单个值处理（按大端序保存），而非 16 个元素的数组
+func handleInt128Tag(fieldDesc *Field, field reflect.StructField) error {
+	if fieldDesc.Type != Int128Type && fieldDesc.Type != Uint128Type {
+		return nil
+	}
+	if isInt128Holder(field.Type) {
+		fieldDesc.IsSlice = false
+		fieldDesc.IsArray = false
+		fieldDesc.Length = 1
+		return nil
+	}
+	if kind := field.Type.Kind(); (kind == reflect.Slice || kind == reflect.Array) && isInt128Holder(field.Type.Elem()) {
+		return nil
+	}
+	return fmt.Errorf("struc: field `%s` of type %v cannot hold a %s value", field.Name, field.Type, fieldDesc.Type)
+}
+
+// putUint128 按字节序写入 128 位整数
+func putUint128(buffer []byte, hi, lo uint64, byteOrder binary.ByteOrder) {
+	if byteOrder == binary.LittleEndian {
+		binary.LittleEndian.PutUint64(buffer[:8], lo)
+		binary.LittleEndian.PutUint64(buffer[8:16], hi)
+		return
+	}
+	binary.BigEndian.PutUint64(buffer[:8], hi)
+	binary.BigEndian.PutUint64(buffer[8:16], lo)
+}
+
+// getUint128 按字节序读取 128 位整数
+func getUint128(buffer []byte, byteOrder binary.ByteOrder) (hi, lo uint64) {
+	if byteOrder == binary.LittleEndian {
+		return binary.LittleEndian.Uint64(buffer[8:16]), binary.LittleEndian.Uint64(buffer[:8])
+	}
+	return binary.BigEndian.Uint64(buffer[:8]), binary.BigEndian.Uint64(buffer[8:16])
+}
+
+// int128Value 从 Go 值中提取 128 位整数的高低位
+// nil 指针视为 0，*big.Int 超出目标类型范围时返回错误
+func (f *Field) int128Value(value reflect.Value, resolvedType Type) (hi, lo uint64, err error) {
+	if !value.IsValid() {
+		return 0, 0, nil
+	}
+	switch value.Type() {
+	case uint128GoType:
+		return value.Field(0).Uint(), value.Field(1).Uint(), nil
+	case bigIntPtrType:
+		if value.IsNil() {
+			return 0, 0, nil
+		}
+		return f.bigIntToUint128(value.Interface().(*big.Int), resolvedType)
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		return f.int128Value(value.Elem(), resolvedType)
+	case reflect.Array:
+		var raw [16]byte
+		reflect.Copy(reflect.ValueOf(raw[:]), value)
+		return binary.BigEndian.Uint64(raw[:8]), binary.BigEndian.Uint64(raw[8:]), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intValue := value.Int()
+		if intValue < 0 {
+			if resolvedType == Uint128Type {
+				return 0, 0, fmt.Errorf("struc: negative value %d for %s field `%s`", intValue, resolvedType, f.Name)
+			}
+			hi = ^uint64(0)
+		}
+		return hi, uint64(intValue), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 0, value.Uint(), nil
+	}
+	return 0, 0, fmt.Errorf("struc: cannot pack %v as %s (field `%s`)", value.Type(), resolvedType, f.Name)
+}
+
+// bigIntToUint128 将 *big.Int 转换为 128 位补码
+func (f *Field) bigIntToUint128(value *big.Int, resolvedType Type) (hi, lo uint64, err error) {
+	if resolvedType == Int128Type {
+		if value.Cmp(minInt128) < 0 || value.Cmp(maxInt128) > 0 {
+			return 0, 0, fmt.Errorf("struc: value %s overflows int128 field `%s`", value, f.Name)
+		}
+	} else if value.Sign() < 0 || value.BitLen() > 128 {
+		return 0, 0, fmt.Errorf("struc: value %s overflows uint128 field `%s`", value, f.Name)
+	}
+
+	twos := value
+	if value.Sign() < 0 {
+		twos = new(big.Int).Add(value, twoTo128)
+	}
+	var raw [16]byte
+	twos.FillBytes(raw[:])
+	return binary.BigEndian.Uint64(raw[:8]), binary.BigEndian.Uint64(raw[8:]), nil
+}
+
+// setInt128Value 将 128 位整数写回 Go 值
+// 普通整数字段无法容纳的值返回错误，而不是静默截断
+func (f *Field) setInt128Value(value reflect.Value, hi, lo uint64, resolvedType Type) error {
+	negative := resolvedType == Int128Type && hi>>63 == 1
+
+	switch value.Type() {
+	case uint128GoType:
+		value.Field(0).SetUint(hi)
+		value.Field(1).SetUint(lo)
+		return nil
+	case bigIntPtrType:
+		if value.IsNil() {
+			value.Set(reflect.New(bigIntPtrType.Elem()))
+		}
+		var raw [16]byte
+		binary.BigEndian.PutUint64(raw[:8], hi)
+		binary.BigEndian.PutUint64(raw[8:], lo)
+		bigValue := value.Interface().(*big.Int).SetBytes(raw[:])
+		if negative {
+			bigValue.Sub(bigValue, twoTo128)
+		}
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return f.setInt128Value(value.Elem(), hi, lo, resolvedType)
+	case reflect.Array:
+		var raw [16]byte
+		binary.BigEndian.PutUint64(raw[:8], hi)
+		binary.BigEndian.PutUint64(raw[8:], lo)
+		reflect.Copy(value, reflect.ValueOf(raw[:]))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intValue := int64(lo)
+		fits := (hi == 0 && intValue >= 0) || (negative && hi == ^uint64(0) && intValue < 0)
+		if !fits || value.OverflowInt(intValue) {
+			return fmt.Errorf("struc: %s value does not fit in field `%s` of type %v", resolvedType, f.Name, value.Type())
+		}
+		value.SetInt(intValue)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if hi != 0 || value.OverflowUint(lo) {
+			return fmt.Errorf("struc: %s value does not fit in field `%s` of type %v", resolvedType, f.Name, value.Type())
+		}
+		value.SetUint(lo)
+		return nil
+	}
+	return fmt.Errorf("struc: cannot unpack %s into %v (field `%s`)", resolvedType, value.Type(), f.Name)
+}
+
+// packInt128 打包 128 位整数字段，支持单个值、切片和数组
+func (f *Field) packInt128(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, options *Options) (int, error) {
+	byteOrder := f.determineByteOrder(options)
+	if !f.IsSlice {
+		hi, lo, err := f.int128Value(fieldValue, resolvedType)
+		if err != nil {
+			return 0, err
+		}
+		putUint128(buffer, hi, lo, byteOrder)
+		return 16, nil
+	}
+
+	dataLength := fieldValue.Len()
+	for i := 0; i < length; i++ {
+		var hi, lo uint64
+		if i < dataLength {
+			var err error
+			if hi, lo, err = f.int128Value(fieldValue.Index(i), resolvedType); err != nil {
+				return 0, fmt.Errorf("failed to pack slice element %d: %w", i, err)
+			}
+		}
+		putUint128(buffer[i*16:], hi, lo, byteOrder)
+	}
+	return length * 16, nil
+}
+
+// unpackInt128 解包 128 位整数字段
+func (f *Field) unpackInt128(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, options *Options) error {
+	byteOrder := f.determineByteOrder(options)
+	if !f.IsSlice {
+		hi, lo := getUint128(buffer, byteOrder)
+		return f.setInt128Value(fieldValue, hi, lo, resolvedType)
+	}
+
+	if !f.IsArray {
+		if fieldValue.Cap() < length {
+			fieldValue.Set(reflect.MakeSlice(fieldValue.Type(), length, length))
+		} else if fieldValue.Len() != length {
+			fieldValue.Set(fieldValue.Slice(0, length))
+		}
+	}
+	for i := 0; i < length && i < fieldValue.Len(); i++ {
+		hi, lo := getUint128(buffer[i*16:], byteOrder)
+		if err := f.setInt128Value(fieldValue.Index(i), hi, lo, resolvedType); err != nil {
+			return fmt.Errorf("failed to unpack slice element %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package struc
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type int128Example struct {
+	Addr  [16]byte  `struc:"uint128"`
+	ID    Uint128   // 未声明标签时默认为 uint128
+	Delta *big.Int  `struc:"int128"`
+	Small int64     `struc:"int128,little"`
+	List  []Uint128 `struc:"[2]uint128,little"`
+}
+
+func TestInt128Encoding(t *testing.T) {
+	in := &int128Example{
+		Addr:  [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 0x01},
+		ID:    Uint128{Hi: 0x0102030405060708, Lo: 0x090a0b0c0d0e0f10},
+		Delta: big.NewInt(-2),
+		Small: -1,
+		List:  []Uint128{{Lo: 1}, {Hi: 1}},
+	}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01,
+		1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("int128 pack: got %x, want %x", buf.Bytes(), want)
+	}
+	if size, err := Sizeof(in); err != nil || size != len(want) {
+		t.Fatalf("int128 sizeof: got %d (%v), want %d", size, err, len(want))
+	}
+
+	out := &int128Example{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("int128 round trip: got %+v, want %+v", out, in)
+	}
+	if got := in.ID.String(); got != "1339673755198158349044581307228491536" {
+		t.Fatalf("Uint128.String: got %s", got)
+	}
+}
+
+func TestInt128Range(t *testing.T) {
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 127)
+	var buf bytes.Buffer
+	err := Pack(&buf, &struct {
+		V *big.Int `struc:"int128"`
+	}{V: tooBig})
+	if err == nil || !strings.Contains(err.Error(), "overflows int128") {
+		t.Fatalf("expected int128 overflow error, got %v", err)
+	}
+
+	// uint128 能容纳 2^127，但解包到 int64 字段时必须报错
+	buf.Reset()
+	if err := Pack(&buf, &struct {
+		V *big.Int `struc:"uint128"`
+	}{V: tooBig}); err != nil {
+		t.Fatal(err)
+	}
+	narrow := &struct {
+		V uint64 `struc:"uint128"`
+	}{}
+	if err := Unpack(bytes.NewReader(buf.Bytes()), narrow); err == nil {
+		t.Fatal("expected error unpacking 2^127 into uint64")
+	}
+
+	_, err = Sizeof(&struct {
+		V string `struc:"uint128"`
+	}{})
+	if err == nil {
+		t.Fatal("expected error for string field tagged uint128")
+	}
+}
+
+func TestInt128NilBigInt(t *testing.T) {
+	// nil *big.Int 按 0 打包，不要求 optional 标签
+	in := &struct {
+		V *big.Int `struc:"int128"`
+	}{}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), make([]byte, 16)) {
+		t.Fatalf("got % x, want 16 zero bytes", buf.Bytes())
+	}
+
+	out := &struct {
+		V *big.Int `struc:"int128"`
+	}{}
+	if err := Unpack(bytes.NewReader(buf.Bytes()), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.V == nil || out.V.Sign() != 0 {
+		t.Fatalf("unpack: got %v, want 0", out.V)
+	}
+}
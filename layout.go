@@ -0,0 +1,158 @@
+package struc
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Layout 描述 C 编译器的结构体布局规则
+//
+// 设置 Options.Layout 后，每个字段按其自然对齐放在相应的偏移处，结构体的对齐取成员对齐的最大值，
+// 并在末尾填充到对齐的整数倍（尾部填充），与 C 编译器生成的内存布局一致。
+//
+// 布局还决定 Size_t/Off_t 的宽度和本机字节序：Options.PtrSize 未设置时取布局的值，
+// 布局的字节序只作用于标签和结构体都未声明字节序的字段。
+type Layout struct {
+	// Name 布局名称，用于调试输出
+	Name string
+
+	// PtrSize 指针、Size_t 和 Off_t 的位数
+	PtrSize int
+
+	// Order 本机字节序
+	Order binary.ByteOrder
+
+	// Int64Align 64 位整数和 double 的对齐字节数（i386 System V ABI 为 4）
+	Int64Align int
+
+	// MaxAlign 成员对齐的上限，对应 #pragma pack(n)；0 表示不限制
+	MaxAlign int
+}
+
+// 常用平台的布局
+var (
+	// LayoutAMD64 x86-64 System V / Windows x64
+	LayoutAMD64 = &Layout{Name: "amd64", PtrSize: 64, Order: binary.LittleEndian, Int64Align: 8}
+
+	// Layout386 i386 System V（long long 和 double 在结构体中按 4 字节对齐）
+	Layout386 = &Layout{Name: "386", PtrSize: 32, Order: binary.LittleEndian, Int64Align: 4}
+
+	// LayoutARM 32 位 ARM EABI
+	LayoutARM = &Layout{Name: "arm", PtrSize: 32, Order: binary.LittleEndian, Int64Align: 8}
+
+	// LayoutARM64 AArch64
+	LayoutARM64 = &Layout{Name: "arm64", PtrSize: 64, Order: binary.LittleEndian, Int64Align: 8}
+
+	// LayoutPacked 成员之间没有任何填充，对应 __attribute__((packed))
+	LayoutPacked = LayoutAMD64.Pack(1)
+)
+
+// Pack 返回成员对齐上限为 n 的布局，对应 #pragma pack(n)
+func (l *Layout) Pack(n int) *Layout {
+	packed := *l
+	packed.MaxAlign = n
+	if n == 1 {
+		packed.Name = "packed"
+	} else {
+		packed.Name = fmt.Sprintf("%s,pack(%d)", l.Name, n)
+	}
+	return &packed
+}
+
+// String 返回布局名称
+func (l *Layout) String() string {
+	return l.Name
+}
+
+// validate 检查布局是否有效
+func (l *Layout) validate() error {
+	switch l.PtrSize {
+	case 16, 32, 64:
+	default:
+		return fmt.Errorf("invalid Layout.PtrSize: %d (must be 16, 32, or 64)", l.PtrSize)
+	}
+	if l.Int64Align != 4 && l.Int64Align != 8 {
+		return fmt.Errorf("invalid Layout.Int64Align: %d (must be 4 or 8)", l.Int64Align)
+	}
+	if l.MaxAlign < 0 || l.MaxAlign&(l.MaxAlign-1) != 0 {
+		return fmt.Errorf("invalid Layout.MaxAlign: %d (must be a power of two)", l.MaxAlign)
+	}
+	return nil
+}
+
+// typeAlign 返回基本类型的自然对齐字节数
+// 没有对应 C 类型的编码（变长整数、奇数宽度整数、C 字符串等）按字节对齐
+func (l *Layout) typeAlign(t Type) int {
+	switch t {
+	case Int16, Uint16, Float16Type, BFloat16Type:
+		return 2
+	case Int32, Uint32, Float32, Complex64, Unix32, DOSDateTime, NTP64, Filetime:
+		return 4
+	case GPSWeek:
+		return 2
+	case Int64, Uint64, Float64, Complex128, Unix64, UnixMs64:
+		return l.Int64Align
+	case Int128Type, Uint128Type:
+		if l.PtrSize == 64 {
+			return 16
+		}
+		return l.Int64Align
+	default:
+		return 1
+	}
+}
+
+// fieldAlign 返回字段在该布局下的对齐字节数（受 MaxAlign 限制）
+func (l *Layout) fieldAlign(f *Field, options *Options) int {
+	var align int
+	switch {
+	case f.BitSize > 0:
+		// 位域按存储单元对齐，只在单元的第一个字段之前填充
+		if !f.bitFirst {
+			return 1
+		}
+		align = l.typeAlign(f.bitUnit)
+	case f.Type == Struct:
+		align = f.NestFields.alignment(options)
+	default:
+		align = l.typeAlign(resolveTypeForOptions(f.Type, options))
+	}
+	if l.MaxAlign > 0 && align > l.MaxAlign {
+		align = l.MaxAlign
+	}
+	return align
+}
+
+// alignment 返回字段起始偏移的对齐字节数：align= 声明的值与布局的自然对齐取较大者
+func (f *Field) alignment(options *Options) int {
+	align := f.Align
+	if layout := options.Layout; layout != nil {
+		if natural := layout.fieldAlign(f, options); natural > align {
+			align = natural
+		}
+	}
+	return align
+}
+
+// alignment 返回结构体的对齐字节数，即成员对齐的最大值
+func (f Fields) alignment(options *Options) int {
+	align := 1
+	for _, field := range f {
+		if field == nil {
+			continue
+		}
+		if fieldAlign := field.alignment(options); fieldAlign > align {
+			align = fieldAlign
+		}
+	}
+	return align
+}
+
+// tailPadding 返回使用布局时结构体末尾需要的填充字节数
+func (f Fields) tailPadding(position int, options *Options) int {
+	if options.Layout == nil {
+		return 0
+	}
+	align := f.alignment(options)
+	return (align - position%align) % align
+}
@@ -0,0 +1,163 @@
+package struc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// layoutMixed 对应 C 结构体：
+//
+//	struct mixed { uint8_t a; uint32_t b; uint16_t c; uint64_t d; uint8_t e; };
+type layoutMixed struct {
+	A uint8
+	B uint32
+	C uint16
+	D uint64
+	E uint8
+}
+
+type layoutOuter struct {
+	X     uint8
+	Inner layoutMixed
+	Len   Size_t
+}
+
+func layoutSample() *layoutMixed {
+	return &layoutMixed{A: 1, B: 0x02020202, C: 0x0303, D: 0x0404040404040404, E: 5}
+}
+
+func TestLayoutOffsets(t *testing.T) {
+	tests := []struct {
+		layout  *Layout
+		offsets []int // B, C, D, E 的偏移
+		size    int
+	}{
+		{LayoutAMD64, []int{4, 8, 16, 24}, 32},
+		{LayoutARM64, []int{4, 8, 16, 24}, 32},
+		{LayoutARM, []int{4, 8, 16, 24}, 32},
+		{Layout386, []int{4, 8, 12, 20}, 24},
+		{LayoutAMD64.Pack(2), []int{2, 6, 8, 16}, 18},
+		{LayoutPacked, []int{1, 5, 7, 15}, 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout.String(), func(t *testing.T) {
+			options := LayoutOptions(tt.layout)
+			in := layoutSample()
+
+			size, err := SizeofWithOptions(in, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size != tt.size {
+				t.Fatalf("sizeof: got %d, want %d", size, tt.size)
+			}
+
+			var buf bytes.Buffer
+			if err := PackWithOptions(&buf, in, options); err != nil {
+				t.Fatal(err)
+			}
+			data := buf.Bytes()
+			if len(data) != tt.size {
+				t.Fatalf("pack: got %d bytes, want %d", len(data), tt.size)
+			}
+			if data[0] != 1 || data[tt.offsets[0]] != 2 || data[tt.offsets[1]] != 3 ||
+				data[tt.offsets[2]] != 4 || data[tt.offsets[3]] != 5 {
+				t.Fatalf("pack: unexpected layout %x", data)
+			}
+
+			out := &layoutMixed{}
+			if err := UnpackWithOptions(bytes.NewReader(data), out, options); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(in, out) {
+				t.Fatalf("round trip: got %+v, want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestLayoutNestedAndSizeT(t *testing.T) {
+	in := &layoutOuter{X: 9, Inner: *layoutSample(), Len: 7}
+
+	options := LayoutOptions(LayoutAMD64)
+	var buf bytes.Buffer
+	if err := PackWithOptions(&buf, in, options); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// Inner 按 8 字节对齐位于偏移 8，占 32 字节；Len 为 64 位
+	if len(data) != 48 || data[8] != 1 || binary.LittleEndian.Uint64(data[40:]) != 7 {
+		t.Fatalf("amd64: unexpected layout %x", data)
+	}
+
+	options = LayoutOptions(Layout386)
+	buf.Reset()
+	if err := PackWithOptions(&buf, in, options); err != nil {
+		t.Fatal(err)
+	}
+	data = buf.Bytes()
+	// Inner 按 4 字节对齐位于偏移 4，占 24 字节；Len 为 32 位
+	if len(data) != 32 || data[4] != 1 || binary.LittleEndian.Uint32(data[28:]) != 7 {
+		t.Fatalf("386: unexpected layout %x", data)
+	}
+
+	out := &layoutOuter{}
+	if err := UnpackWithOptions(bytes.NewReader(data), out, options); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestLayoutOptionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *Options
+		want    string
+	}{
+		{"ptr size conflict", &Options{Layout: LayoutAMD64, PtrSize: 32}, "conflicts"},
+		{"invalid pack", &Options{Layout: LayoutAMD64.Pack(3)}, "power of two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SizeofWithOptions(layoutSample(), tt.options)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+type layoutTagged struct {
+	A uint16
+	B uint32 `struc:"uint32,big"`
+}
+
+func TestLayoutOrderIsDefault(t *testing.T) {
+	// 布局的字节序只作用于未声明字节序的字段，也不写回调用方的选项
+	options := &Options{Layout: LayoutAMD64}
+	in := &layoutTagged{A: 0x0102, B: 0x03040506}
+	var buf bytes.Buffer
+	if err := PackWithOptions(&buf, in, options); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x02, 0x01, 0, 0, 0x03, 0x04, 0x05, 0x06}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got % x, want % x", buf.Bytes(), want)
+	}
+	if options.Order != nil {
+		t.Fatalf("Options.Order was modified to %v", options.Order)
+	}
+
+	out := &layoutTagged{}
+	if err := UnpackWithOptions(bytes.NewReader(want), out, options); err != nil {
+		t.Fatal(err)
+	}
+	if *out != *in {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
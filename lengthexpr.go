@@ -0,0 +1,185 @@
+package struc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// 长度表达式
+//
+// sizefrom= 除了字段名，还可以是引用之前字段的整数表达式，例如 IPv4 选项的长度
+// sizefrom=IHL*4-20。打包时长度字段通过 expr= 声明逆运算，x 为实际长度：
+//
+//	IHL     uint8  `struc:"uint8,sizeof=Options,expr=(x+20)/4"`
+//	Options []byte `struc:"sizefrom=IHL*4-20"`
+//
+// 逆运算中的除法必须整除，否则打包时报错（例如 21 字节的选项无法用 IHL 表示）。
+
+// probeLengthLimit 解析时校验 expr= 与 sizefrom= 互逆所尝试的最大长度
+const probeLengthLimit = 1024
+
+// isExprIdent 判断字符串是否为单个标识符（而非表达式）
+func isExprIdent(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isExprIdentChar(s[i]) {
+			return false
+		}
+	}
+	return s != "" && (s[0] < '0' || s[0] > '9')
+}
+
+// handleLengthExpr 编译 sizefrom= 中的长度表达式
+func handleLengthExpr(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField) error {
+	expr, err := parseFieldExpr(fieldTag.Sizefrom)
+	if err != nil {
+		return fmt.Errorf("%w (field `%s`)", err, field.Name)
+	}
+	if err := expr.resolveFields(structType, field.Index[0]); err != nil {
+		return fmt.Errorf("%w (field `%s`)", err, field.Name)
+	}
+	if len(expr.refs) == 0 {
+		return fmt.Errorf("struc: `sizefrom=%s` must refer to a field; use a fixed length instead (field `%s`)", fieldTag.Sizefrom, field.Name)
+	}
+	fieldDesc.lengthExpr = expr
+	// Sizefrom 指向长度字段（由 sizeof 声明）或表达式中的第一个字段，使已有的长度判断保持有效
+	if fieldDesc.Sizefrom == nil {
+		fieldDesc.Sizefrom = expr.refs[0].index
+	}
+	return nil
+}
+
+// handleInverseExprTag 编译长度字段上 expr= 声明的逆运算
+func handleInverseExprTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField) error {
+	if fieldTag.Expr == "" {
+		return nil
+	}
+	if fieldDesc.Sizeof == nil && fieldDesc.Bytesof == nil {
+		return fmt.Errorf("struc: `expr=%s` requires `sizeof=` or `bytesof=` (field `%s`)", fieldTag.Expr, field.Name)
+	}
+	expr, err := parseVarExpr(fieldTag.Expr)
+	if err != nil {
+		return fmt.Errorf("%w (field `%s`)", err, field.Name)
+	}
+	if err := expr.resolveFields(structType, field.Index[0]); err != nil {
+		return fmt.Errorf("%w (field `%s`)", err, field.Name)
+	}
+	fieldDesc.inverseExpr = expr
+	return nil
+}
+
+// lengthTarget 返回 sizeof/bytesof 字段所描述的数据字段索引，没有时返回 -1
+func (f *Field) lengthTarget() int {
+	switch {
+	case len(f.Sizeof) == 1 && f.sizeofUp == 0:
+		return f.Sizeof[0]
+	case f.Bytesof != nil:
+		return f.Bytesof[0]
+	default:
+		return -1
+	}
+}
+
+// linkLengthExprs 校验长度表达式与长度字段上的逆运算是否成对出现并且互逆
+func linkLengthExprs(fields Fields, structType reflect.Type) error {
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+		index := field.lengthTarget()
+		if index < 0 {
+			continue
+		}
+		target := fields[index]
+		if target == nil || target.lengthExpr == nil {
+			if field.inverseExpr != nil {
+				return fmt.Errorf("struc: `expr=%s` of field `%s` requires its target to use a `sizefrom=` expression", field.inverseExpr, field.Name)
+			}
+			continue
+		}
+		if field.inverseExpr == nil {
+			return fmt.Errorf("struc: length field `%s` needs `expr=` to invert `sizefrom=%s` of field `%s`", field.Name, target.lengthExpr, target.Name)
+		}
+		if err := probeLengthExprs(field, target, structType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// probeLengthExprs 在两个表达式只依赖长度字段时，对一段长度逐一验证 sizefrom(expr(x)) == x
+func probeLengthExprs(field, target *Field, structType reflect.Type) error {
+	if len(field.inverseExpr.refs) > 0 {
+		return nil
+	}
+	for _, ref := range target.lengthExpr.refs {
+		if len(ref.index) != 1 || ref.index[0] != field.Index {
+			return nil
+		}
+	}
+
+	probe := reflect.New(structType).Elem()
+	lengthValue := probe.Field(field.Index)
+	for x := int64(0); x <= probeLengthLimit; x++ {
+		value, err := field.inverseExpr.evalWith(probe, x)
+		if err != nil || !setLengthValue(lengthValue, value) {
+			// 无法表示的长度在打包时报错
+			continue
+		}
+		back, err := target.lengthExpr.eval(probe)
+		if err == nil && back != x {
+			return fmt.Errorf("struc: `expr=%s` of field `%s` does not invert `sizefrom=%s`: length %d is stored as %d and read back as %d",
+				field.inverseExpr, field.Name, target.lengthExpr, x, value, back)
+		}
+	}
+	return nil
+}
+
+// setLengthValue 将长度值写入整数字段，值为负或超出字段范围时返回 false
+func setLengthValue(fieldValue reflect.Value, value int64) bool {
+	switch fieldValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value < 0 || fieldValue.OverflowInt(value) {
+			return false
+		}
+		fieldValue.SetInt(value)
+	default:
+		if value < 0 || fieldValue.OverflowUint(uint64(value)) {
+			return false
+		}
+		fieldValue.SetUint(uint64(value))
+	}
+	return true
+}
+
+// sizeofValue 返回 sizeof/bytesof 字段在打包时应写入的值，声明了 expr= 时按表达式换算
+func (f Fields) sizeofValue(structValue reflect.Value, field *Field, options *Options) (int64, error) {
+	length := int64(f.sizeofLength(structValue, field, options))
+	if field.inverseExpr == nil {
+		return length, nil
+	}
+	value, err := field.inverseExpr.evalWith(structValue, length)
+	if err != nil {
+		return 0, fmt.Errorf("%w (field `%s`, length %d)", err, field.Name, length)
+	}
+	probe := reflect.New(structValue.Field(field.Index).Type()).Elem()
+	if !setLengthValue(probe, value) {
+		return 0, fmt.Errorf("struc: `expr=%s` gives %d for length %d, which does not fit field `%s` (%v)",
+			field.inverseExpr, value, length, field.Name, probe.Type())
+	}
+	return value, nil
+}
+
+// lengthFrom 返回 sizefrom 引用的长度，sizefrom 为表达式时对表达式求值
+func (f Fields) lengthFrom(structValue reflect.Value, field *Field, options *Options) (int, error) {
+	if field.lengthExpr == nil {
+		return f.sizefrom(refBase(structValue, field.sizefromUp, options), field.Sizefrom), nil
+	}
+	length, err := field.lengthExpr.eval(structValue)
+	if err != nil {
+		return 0, fmt.Errorf("%w (field `%s`)", err, field.Name)
+	}
+	if length < 0 {
+		return 0, fmt.Errorf("struc: `sizefrom=%s` gives negative length %d (field `%s`)", field.lengthExpr, length, field.Name)
+	}
+	return int(length), nil
+}
@@ -0,0 +1,139 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type lengthExprIPv4 struct {
+	VersionIHL uint8  `struc:"uint8"`
+	IHL        uint8  `struc:"uint8,sizeof=Options,expr=(x+20)/4"`
+	Options    []byte `struc:"sizefrom=IHL*4-20"`
+	Protocol   uint8
+}
+
+type lengthExprRecord struct {
+	Count uint8    `struc:"uint8,sizeof=Items,expr=x-1"`
+	Items []uint16 `struc:"[]uint16,sizefrom=Count+1"`
+}
+
+func TestLengthExprRoundTrip(t *testing.T) {
+	in := &lengthExprIPv4{
+		VersionIHL: 4,
+		Options:    []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		Protocol:   6,
+	}
+	want := []byte{4, 7, 1, 2, 3, 4, 5, 6, 7, 8, 6}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), want)
+	}
+	if in.IHL != 7 {
+		t.Fatalf("IHL not written back: %d", in.IHL)
+	}
+
+	out := &lengthExprIPv4{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestLengthExprElementCount(t *testing.T) {
+	in := &lengthExprRecord{Items: []uint16{1, 2, 3}}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{2, 0, 1, 0, 2, 0, 3}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), want)
+	}
+
+	out := &lengthExprRecord{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestLengthExprPackErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"non-integral", &lengthExprIPv4{Options: []byte{1, 2, 3}}, "not integral"},
+		{"negative", &lengthExprRecord{}, "does not fit field `Count`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Pack(&buf, tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLengthExprUnpackNegative(t *testing.T) {
+	// IHL=4 表示 -4 字节的选项
+	err := Unpack(bytes.NewReader([]byte{4, 4, 6}), &lengthExprIPv4{})
+	if err == nil || !strings.Contains(err.Error(), "negative length") {
+		t.Fatalf("expected negative length error, got %v", err)
+	}
+}
+
+func TestLengthExprTagErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"missing inverse", &struct {
+			IHL     uint8  `struc:"uint8,sizeof=Options"`
+			Options []byte `struc:"sizefrom=IHL*4-20"`
+		}{}, "needs `expr=`"},
+		{"inverse without expression", &struct {
+			N    uint8  `struc:"uint8,sizeof=Data,expr=x/2"`
+			Data []byte `struc:"sizefrom=N"`
+		}{}, "requires its target to use a `sizefrom=` expression"},
+		{"expr without sizeof", &struct {
+			N uint8 `struc:"uint8,expr=x/2"`
+		}{}, "requires `sizeof=`"},
+		{"not an inverse", &struct {
+			IHL     uint8  `struc:"uint8,sizeof=Options,expr=(x+20)/4"`
+			Options []byte `struc:"sizefrom=IHL*4-16"`
+		}{}, "does not invert"},
+		{"later field", &struct {
+			Options []byte `struc:"sizefrom=IHL*4-20"`
+			IHL     uint8
+		}{}, "must precede"},
+		{"constant", &struct {
+			Data []byte `struc:"sizefrom=2*4"`
+		}{}, "must refer to a field"},
+		{"unknown variable", &struct {
+			N    uint8  `struc:"uint8,sizeof=Data,expr=y*2"`
+			Data []byte `struc:"sizefrom=N/2"`
+		}{}, "`y`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sizeof(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package struc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// 小浮点数字段
+//
+// float32/float64 字段可以按更窄的浮点格式存放：
+//
+//	float16   IEEE 754 binary16（1 位符号、5 位指数、10 位尾数）
+//	bfloat16  Brain Float（1 位符号、8 位指数、7 位尾数）
+//	fp8e4m3   OCP FP8 E4M3（1 位符号、4 位指数、3 位尾数，无无穷大，最大值 448）
+//	fp8e5m2   OCP FP8 E5M2（1 位符号、5 位指数、2 位尾数）
+//
+// Float16、BFloat16、FP8E4M3 和 FP8E5M2 类型的字段（及其指针、切片和数组）不需要标签。
+// 打包时按就近舍入（偶数优先），保留次正规数和 NaN 的高位载荷；超出范围的值变为无穷大，
+// E4M3 没有无穷大，溢出和无穷大都变为 NaN。
+
+// minifloatFormat 描述一种小浮点格式
+type minifloatFormat struct {
+	expBits  uint // 指数位数
+	mantBits uint // 尾数位数
+	finite   bool // 没有无穷大，只有指数和尾数全 1 表示 NaN（E4M3）
+}
+
+var (
+	float16Format  = minifloatFormat{expBits: 5, mantBits: 10}
+	bfloat16Format = minifloatFormat{expBits: 8, mantBits: 7}
+	fp8e4m3Format  = minifloatFormat{expBits: 4, mantBits: 3, finite: true}
+	fp8e5m2Format  = minifloatFormat{expBits: 5, mantBits: 2}
+)
+
+// minifloatFormatOf 返回线上类型对应的小浮点格式
+func minifloatFormatOf(t Type) minifloatFormat {
+	switch t {
+	case Float16Type:
+		return float16Format
+	case BFloat16Type:
+		return bfloat16Format
+	case FP8E4M3Type:
+		return fp8e4m3Format
+	default:
+		return fp8e5m2Format
+	}
+}
+
+// bias 返回指数偏移值
+func (m minifloatFormat) bias() int {
+	return 1<<(m.expBits-1) - 1
+}
+
+// overflow 返回超出范围时的编码：无穷大，或 E4M3 的 NaN
+func (m minifloatFormat) overflow(sign uint16) uint16 {
+	expMax := uint16(1)<<m.expBits - 1
+	if m.finite {
+		return sign | expMax<<m.mantBits | (1<<m.mantBits - 1)
+	}
+	return sign | expMax<<m.mantBits
+}
+
+// encode 将 float64 按就近舍入（偶数优先）转换为小浮点编码
+func (m minifloatFormat) encode(value float64) uint16 {
+	bits := math.Float64bits(value)
+	sign := uint16(bits>>63) << (m.expBits + m.mantBits)
+	exp := int(bits>>52) & 0x7ff
+	mant := bits & (1<<52 - 1)
+	expMax := uint16(1)<<m.expBits - 1
+
+	switch {
+	case exp == 0x7ff && mant != 0:
+		if m.finite {
+			return m.overflow(sign)
+		}
+		// 保留 NaN 载荷的高位，截断后为 0 时设置 quiet 位，避免变成无穷大
+		payload := uint16(mant >> (52 - m.mantBits))
+		if payload == 0 {
+			payload = 1 << (m.mantBits - 1)
+		}
+		return sign | expMax<<m.mantBits | payload
+	case exp == 0x7ff:
+		return m.overflow(sign)
+	case exp == 0:
+		// 零和 float64 次正规数，远小于任何小浮点格式的最小次正规数
+		return sign
+	}
+
+	// 目标格式的偏移指数小于 1 时为次正规数，尾数多右移相应的位数
+	biased := exp - 1023 + m.bias()
+	shift := 52 - m.mantBits
+	if biased < 1 {
+		shift += uint(1 - biased)
+		biased = 1
+	}
+	var encoded uint64
+	if shift < 64 {
+		// 舍入进位会自然进入指数位
+		encoded = uint64(biased-1)<<m.mantBits + roundShiftEven(mant|1<<52, shift)
+	}
+
+	limit := uint64(expMax) << m.mantBits
+	if m.finite {
+		limit |= 1<<m.mantBits - 1
+	}
+	if encoded >= limit {
+		return m.overflow(sign)
+	}
+	return sign | uint16(encoded)
+}
+
+// decode 将小浮点编码转换为 float64，转换是精确的
+func (m minifloatFormat) decode(raw uint16) float64 {
+	negative := raw>>(m.expBits+m.mantBits)&1 != 0
+	expMax := int(1)<<m.expBits - 1
+	exp := int(raw>>m.mantBits) & expMax
+	mantMask := uint16(1)<<m.mantBits - 1
+	mant := raw & mantMask
+
+	var value float64
+	switch {
+	case exp == expMax && !m.finite && mant == 0:
+		value = math.Inf(1)
+	case exp == expMax && (!m.finite || mant == mantMask):
+		bits := uint64(0x7ff)<<52 | uint64(mant)<<(52-m.mantBits)
+		if negative {
+			bits |= 1 << 63
+		}
+		return math.Float64frombits(bits)
+	case exp == 0:
+		value = math.Ldexp(float64(mant), 1-m.bias()-int(m.mantBits))
+	default:
+		value = math.Ldexp(float64(mant|1<<m.mantBits), exp-m.bias()-int(m.mantBits))
+	}
+	if negative {
+		value = math.Copysign(value, -1)
+	}
+	return value
+}
+
+// roundShiftEven 将 value 右移 shift 位，按就近舍入（偶数优先）处理被移出的位
+func roundShiftEven(value uint64, shift uint) uint64 {
+	quotient := value >> shift
+	remainder := value & (1<<shift - 1)
+	half := uint64(1) << (shift - 1)
+	if remainder > half || (remainder == half && quotient&1 == 1) {
+		quotient++
+	}
+	return quotient
+}
+
+// writeMinifloat 将浮点值按小浮点格式写入缓冲区
+func writeMinifloat(buffer []byte, value float64, resolvedType Type, byteOrder binary.ByteOrder) {
+	raw := minifloatFormatOf(resolvedType).encode(value)
+	if resolvedType.Size() == 1 {
+		buffer[0] = byte(raw)
+		return
+	}
+	unsafePutUint16(buffer, raw, byteOrder)
+}
+
+// readMinifloat 从缓冲区读取小浮点值
+func readMinifloat(buffer []byte, resolvedType Type, byteOrder binary.ByteOrder) float64 {
+	var raw uint16
+	if resolvedType.Size() == 1 {
+		raw = uint16(buffer[0])
+	} else {
+		raw = unsafeGetUint16(buffer, byteOrder)
+	}
+	return minifloatFormatOf(resolvedType).decode(raw)
+}
+
+var (
+	float16GoType  = reflect.TypeOf(Float16(0))
+	bfloat16GoType = reflect.TypeOf(BFloat16(0))
+	fp8e4m3GoType  = reflect.TypeOf(FP8E4M3(0))
+	fp8e5m2GoType  = reflect.TypeOf(FP8E5M2(0))
+)
+
+// defaultMinifloatType 返回未声明标签时 Go 类型对应的小浮点类型
+// 同样适用于它们的指针、切片和数组
+func defaultMinifloatType(goType reflect.Type) (Type, bool) {
+	switch goType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		goType = goType.Elem()
+	}
+	switch goType {
+	case float16GoType:
+		return Float16Type, true
+	case bfloat16GoType:
+		return BFloat16Type, true
+	case fp8e4m3GoType:
+		return FP8E4M3Type, true
+	case fp8e5m2GoType:
+		return FP8E5M2Type, true
+	}
+	return Invalid, false
+}
+
+// handleMinifloatTag 校验小浮点字段的 Go 类型
+func handleMinifloatTag(fieldDesc *Field, field reflect.StructField) error {
+	if !fieldDesc.Type.IsMinifloat() {
+		return nil
+	}
+	if fieldDesc.kind != reflect.Float32 && fieldDesc.kind != reflect.Float64 {
+		return fmt.Errorf("struc: %s field `%s` must be a float32 or float64, got %v", fieldDesc.Type, field.Name, field.Type)
+	}
+	return nil
+}
+
+// packMinifloatSlice 打包小浮点数的切片或数组
+// float32/float64 元素直接通过 unsafe 访问底层数组批量转换，避免逐元素 reflect
+func (f *Field) packMinifloatSlice(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, byteOrder binary.ByteOrder) int {
+	elementSize := resolvedType.Size()
+	totalSize := length * elementSize
+	dataLength := fieldValue.Len()
+	if dataLength > length {
+		dataLength = length
+	}
+
+	if dataLength > 0 && !packMinifloatFast(buffer, fieldValue, dataLength, resolvedType, byteOrder) {
+		for i := 0; i < dataLength; i++ {
+			writeMinifloat(buffer[i*elementSize:], fieldValue.Index(i).Float(), resolvedType, byteOrder)
+		}
+	}
+	if dataLength < length {
+		memclr(buffer[dataLength*elementSize : totalSize])
+	}
+	return totalSize
+}
+
+// packMinifloatFast 针对 float32/float64 元素的批量打包路径
+// 无法直接取得底层数组时返回 false，由调用方回退到 reflect
+func packMinifloatFast(buffer []byte, fieldValue reflect.Value, count int, resolvedType Type, byteOrder binary.ByteOrder) bool {
+	ptr, ok := sliceDataPointer(fieldValue)
+	if !ok {
+		return false
+	}
+	format := minifloatFormatOf(resolvedType)
+	switch fieldValue.Type().Elem().Kind() {
+	case reflect.Float32:
+		src := unsafe.Slice((*float32)(ptr), count)
+		if resolvedType.Size() == 1 {
+			for i, v := range src {
+				buffer[i] = byte(format.encode(float64(v)))
+			}
+			return true
+		}
+		for i, v := range src {
+			unsafePutUint16(buffer[i*2:], format.encode(float64(v)), byteOrder)
+		}
+	case reflect.Float64:
+		src := unsafe.Slice((*float64)(ptr), count)
+		if resolvedType.Size() == 1 {
+			for i, v := range src {
+				buffer[i] = byte(format.encode(v))
+			}
+			return true
+		}
+		for i, v := range src {
+			unsafePutUint16(buffer[i*2:], format.encode(v), byteOrder)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// unpackMinifloatSlice 解包小浮点数的切片或数组
+func (f *Field) unpackMinifloatSlice(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, byteOrder binary.ByteOrder) {
+	if !f.IsArray {
+		if fieldValue.Cap() < length {
+			fieldValue.Set(reflect.MakeSlice(fieldValue.Type(), length, length))
+		} else if fieldValue.Len() != length {
+			fieldValue.Set(fieldValue.Slice(0, length))
+		}
+	}
+	if length > fieldValue.Len() {
+		length = fieldValue.Len()
+	}
+	if length == 0 || unpackMinifloatFast(buffer, fieldValue, length, resolvedType, byteOrder) {
+		return
+	}
+
+	elementSize := resolvedType.Size()
+	for i := 0; i < length; i++ {
+		fieldValue.Index(i).SetFloat(readMinifloat(buffer[i*elementSize:], resolvedType, byteOrder))
+	}
+}
+
+// unpackMinifloatFast 针对 float32/float64 元素的批量解包路径
+func unpackMinifloatFast(buffer []byte, fieldValue reflect.Value, count int, resolvedType Type, byteOrder binary.ByteOrder) bool {
+	ptr, ok := sliceDataPointer(fieldValue)
+	if !ok {
+		return false
+	}
+	elementSize := resolvedType.Size()
+	switch fieldValue.Type().Elem().Kind() {
+	case reflect.Float32:
+		dst := unsafe.Slice((*float32)(ptr), count)
+		for i := range dst {
+			dst[i] = float32(readMinifloat(buffer[i*elementSize:], resolvedType, byteOrder))
+		}
+	case reflect.Float64:
+		dst := unsafe.Slice((*float64)(ptr), count)
+		for i := range dst {
+			dst[i] = readMinifloat(buffer[i*elementSize:], resolvedType, byteOrder)
+		}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+package struc
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type minifloatRecord struct {
+	Half     Float16
+	Little   Float16    `struc:"little"`
+	Brain    float32    `struc:"bfloat16"`
+	Weights  [3]float32 `struc:"[3]bfloat16,little"`
+	Count    uint8      `struc:"uint8,sizeof=Grads"`
+	Grads    []FP8E5M2
+	Acts     [2]FP8E4M3
+	Optional *Float16
+}
+
+func TestMinifloatFields(t *testing.T) {
+	optional := Float16(0.1)
+	in := &minifloatRecord{
+		Half:     1.5,
+		Little:   -2,
+		Brain:    3.140625,
+		Weights:  [3]float32{1, -0.5, 0},
+		Grads:    []FP8E5M2{0.25, 57344},
+		Acts:     [2]FP8E4M3{448, -0.015625},
+		Optional: &optional,
+	}
+	want := []byte{
+		0x3e, 0x00,
+		0x00, 0xc0, // 小端序
+		0x40, 0x49,
+		0x80, 0x3f, 0x00, 0xbf, 0x00, 0x00,
+		2,
+		0x34, 0x7b,
+		0x7e, 0x88,
+		0x2e, 0x66,
+	}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got % x, want % x", buf.Bytes(), want)
+	}
+	if size, err := Sizeof(in); err != nil || size != len(want) {
+		t.Fatalf("sizeof: got %d (%v), want %d", size, err, len(want))
+	}
+
+	out := &minifloatRecord{Optional: new(Float16)}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	rounded := Float16(0.0999755859375)
+	in.Count = 2
+	in.Optional = &rounded
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+
+	format, err := GetFormatString(&struct {
+		A Float16
+		B [4]FP8E4M3
+	}{})
+	if err != nil || format != ">ecccc" {
+		t.Fatalf("format: got %q (%v)", format, err)
+	}
+}
+
+func TestMinifloatEncoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   Type
+		value float64
+		raw   uint16
+	}{
+		{"float16 min subnormal", Float16Type, math.Ldexp(1, -24), 0x0001},
+		{"float16 half min subnormal ties to zero", Float16Type, math.Ldexp(1, -25), 0x0000},
+		{"float16 above half min subnormal", Float16Type, math.Ldexp(1.5, -25), 0x0001},
+		{"float16 subnormal rounds to normal", Float16Type, math.Ldexp(1, -14) - math.Ldexp(1, -26), 0x0400},
+		{"float16 tie to even", Float16Type, 1 + math.Ldexp(1, -11), 0x3c00},
+		{"float16 tie to odd rounds up", Float16Type, 1 + 3*math.Ldexp(1, -11), 0x3c02},
+		{"float16 max", Float16Type, 65519, 0x7bff},
+		{"float16 overflow", Float16Type, 65520, 0x7c00},
+		{"float16 negative zero", Float16Type, math.Copysign(0, -1), 0x8000},
+		{"bfloat16 pi", BFloat16Type, math.Pi, 0x4049},
+		{"bfloat16 tie to even", BFloat16Type, 1 + math.Ldexp(1, -8), 0x3f80},
+		{"bfloat16 min subnormal", BFloat16Type, math.Ldexp(1, -133), 0x0001},
+		{"bfloat16 overflow", BFloat16Type, math.MaxFloat64, 0x7f80},
+		{"e4m3 max", FP8E4M3Type, 448, 0x7e},
+		{"e4m3 tie below nan", FP8E4M3Type, 464, 0x7e},
+		{"e4m3 overflow", FP8E4M3Type, 465, 0x7f},
+		{"e4m3 infinity", FP8E4M3Type, math.Inf(-1), 0xff},
+		{"e4m3 min subnormal", FP8E4M3Type, math.Ldexp(1, -9), 0x01},
+		{"e5m2 max", FP8E5M2Type, 57344, 0x7b},
+		{"e5m2 overflow", FP8E5M2Type, 61440, 0x7c},
+		{"e5m2 min subnormal", FP8E5M2Type, math.Ldexp(1, -16), 0x01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := minifloatFormatOf(tt.typ).encode(tt.value); got != tt.raw {
+				t.Fatalf("encode(%v): got %#04x, want %#04x", tt.value, got, tt.raw)
+			}
+		})
+	}
+}
+
+func TestMinifloatRoundTrip(t *testing.T) {
+	// 每个编码解码后再编码都应得到原值，包括次正规数和 NaN 载荷
+	for _, typ := range []Type{Float16Type, BFloat16Type, FP8E4M3Type, FP8E5M2Type} {
+		format := minifloatFormatOf(typ)
+		count := 1 << (8 * typ.Size())
+		for raw := 0; raw < count; raw++ {
+			value := format.decode(uint16(raw))
+			if got := format.encode(value); got != uint16(raw) {
+				t.Fatalf("%s: %#04x decodes to %v and encodes to %#04x", typ, raw, value, got)
+			}
+			if !math.IsNaN(value) && !math.IsInf(value, 0) && float64(float32(value)) != value {
+				t.Fatalf("%s: %#04x decodes to %v, which is not exact in float32", typ, raw, value)
+			}
+		}
+	}
+
+	if !math.IsNaN(fp8e4m3Format.decode(0x7f)) || math.IsNaN(fp8e4m3Format.decode(0x78)) {
+		t.Fatal("e4m3: only the all-ones encoding is NaN")
+	}
+}
+
+func TestMinifloatErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{"integer field", &struct {
+			V int16 `struc:"float16"`
+		}{}, "must be a float32 or float64"},
+		{"integer slice", &struct {
+			V []uint8 `struc:"[4]fp8e4m3"`
+		}{}, "must be a float32 or float64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Pack(&bytes.Buffer{}, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package struc
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// 压缩 BCD 与 ASCII 数字字段
+//
+// 整数字段可以按以下定宽编码保存：
+//
+//	bcd          压缩 BCD，每字节两位十进制数，宽度由 Go 类型的最大位数决定
+//	[N]bcd       N 字节的压缩 BCD（2N 位）
+//	octal=N      N 字节的 ASCII 八进制数（tar、cpio odc 头部）
+//	decimal=N    N 字节的 ASCII 十进制数（ar 头部），有符号字段可以带负号
+//	hexascii=N   N 字节的 ASCII 十六进制数（cpio newc 头部），打包时使用大写字母
+//
+// ASCII 数字默认右对齐并以 '0' 填充；justify=left 左对齐并以空格填充，pad=zero/space/nul 指定填充字符，
+// term=nul/space 在最后一个字节写入结束符（如 tar 的 "0000644\x00"）。
+// BCD 默认右对齐并以 0 填充，justify=left 左对齐并以 F 半字节填充（EMV 的 cn 格式）。
+//
+// 解包时忽略两端的空格和 NUL 以及末尾的 F 半字节，遇到其它非数字字符时报错；
+// 打包时数字放不下或值为负数（decimal 除外）时报错。
+
+// numericSpec 是 BCD 和 ASCII 数字字段的格式
+type numericSpec struct {
+	base    int  // ASCII 数字的进制，BCD 为 10
+	left    bool // 是否左对齐
+	pad     byte // ASCII 数字的填充字符
+	term    byte // ASCII 数字的结束符
+	hasTerm bool // 最后一个字节是否为结束符
+}
+
+// numericBases 定义了 ASCII 数字类型的进制
+var numericBases = map[Type]int{
+	BCD:      10,
+	Octal:    8,
+	Decimal:  10,
+	HexASCII: 16,
+}
+
+// numericPadChars 定义了 pad= 的取值
+var numericPadChars = map[string]byte{
+	"zero":  '0',
+	"space": ' ',
+	"nul":   0,
+}
+
+// bcdWidth 返回未声明宽度的 bcd 字段按 Go 类型最大位数所需的字节数
+func bcdWidth(kind reflect.Kind) int {
+	switch kind {
+	case reflect.Int8, reflect.Uint8:
+		return 2 // 3 位
+	case reflect.Int16, reflect.Uint16:
+		return 3 // 5 位
+	case reflect.Int32, reflect.Uint32:
+		return 5 // 10 位
+	default:
+		return 10 // 20 位
+	}
+}
+
+// handleNumericTag 处理 bcd、octal=、decimal=、hexascii= 字段及其对齐、填充和结束符选项
+func handleNumericTag(fieldDesc *Field, fieldTag *strucTag, field reflect.StructField) error {
+	base, ok := numericBases[fieldDesc.Type]
+	if !ok {
+		if fieldTag.Justify != "" || fieldTag.Pad != "" || fieldTag.Term != "" {
+			return fmt.Errorf("struc: `justify=`, `pad=` and `term=` only apply to bcd and ASCII numeric fields (field `%s`)", field.Name)
+		}
+		return nil
+	}
+
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return fmt.Errorf("struc: %s field `%s` must be an integer, got %v", fieldDesc.Type, field.Name, field.Type)
+	}
+	if fieldDesc.Sizefrom != nil || fieldDesc.Prefix != Invalid || fieldDesc.BitSize > 0 {
+		return fmt.Errorf("struc: %s field `%s` has a fixed width and cannot have a length", fieldDesc.Type, field.Name)
+	}
+
+	// [N]bcd 等写法中的 N 是字段的字节宽度，而不是数组长度
+	width := fieldTag.Width
+	if width == 0 && fieldDesc.IsSlice {
+		width = fieldDesc.Length
+	}
+	if width == 0 && fieldDesc.Type == BCD {
+		width = bcdWidth(fieldType.Kind())
+	}
+	if width <= 0 {
+		return fmt.Errorf("struc: %s field `%s` needs a positive width", fieldDesc.Type, field.Name)
+	}
+
+	spec := &numericSpec{base: base}
+	switch fieldTag.Justify {
+	case "", "right":
+	case "left":
+		spec.left = true
+	default:
+		return fmt.Errorf("struc: invalid `justify=%s` (must be left or right) (field `%s`)", fieldTag.Justify, field.Name)
+	}
+
+	if fieldDesc.Type == BCD {
+		if fieldTag.Pad != "" || fieldTag.Term != "" {
+			return fmt.Errorf("struc: bcd field `%s` does not support `pad=` or `term=`", field.Name)
+		}
+	} else {
+		spec.pad = '0'
+		if spec.left {
+			spec.pad = ' '
+		}
+		if fieldTag.Pad != "" {
+			if spec.pad, ok = numericPadChars[fieldTag.Pad]; !ok {
+				return fmt.Errorf("struc: invalid `pad=%s` (must be zero, space or nul) (field `%s`)", fieldTag.Pad, field.Name)
+			}
+			if spec.left && spec.pad == '0' {
+				return fmt.Errorf("struc: left-justified field `%s` cannot be padded with zeros", field.Name)
+			}
+		}
+		if fieldTag.Term != "" {
+			if spec.term, ok = numericPadChars[fieldTag.Term]; !ok || spec.term == '0' {
+				return fmt.Errorf("struc: invalid `term=%s` (must be nul or space) (field `%s`)", fieldTag.Term, field.Name)
+			}
+			spec.hasTerm = true
+			if width < 2 {
+				return fmt.Errorf("struc: %s field `%s` is too narrow for a terminator", fieldDesc.Type, field.Name)
+			}
+		}
+	}
+
+	fieldDesc.IsSlice = false
+	fieldDesc.IsArray = false
+	fieldDesc.Length = width
+	fieldDesc.numeric = spec
+	return nil
+}
+
+// numericInteger 返回整数字段的绝对值和符号，nil 指针视为 0
+func numericInteger(fieldValue reflect.Value) (uint64, bool) {
+	if !fieldValue.IsValid() {
+		return 0, false
+	}
+	switch fieldValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value := fieldValue.Int()
+		if value < 0 {
+			return uint64(-value), true
+		}
+		return uint64(value), false
+	default:
+		return fieldValue.Uint(), false
+	}
+}
+
+// packNumeric 打包 BCD 或 ASCII 数字字段
+func (f *Field) packNumeric(buffer []byte, fieldValue reflect.Value, resolvedType Type) (int, error) {
+	if f.IsPointer {
+		fieldValue = fieldValue.Elem()
+	}
+	value, negative := numericInteger(fieldValue)
+	if negative && resolvedType != Decimal {
+		return 0, fmt.Errorf("struc: negative value -%d for %s field `%s`", value, resolvedType, f.Name)
+	}
+
+	if resolvedType == BCD {
+		return f.Length, f.packBCD(buffer[:f.Length], value)
+	}
+
+	digits := strconv.FormatUint(value, f.numeric.base)
+	if resolvedType == HexASCII {
+		digits = string(bytes.ToUpper([]byte(digits)))
+	}
+	if negative {
+		digits = "-" + digits
+	}
+
+	field := buffer[:f.Length]
+	if f.numeric.hasTerm {
+		field[len(field)-1] = f.numeric.term
+		field = field[:len(field)-1]
+	}
+	if len(digits) > len(field) {
+		return 0, fmt.Errorf("struc: %s does not fit in %d %s digits (field `%s`)", digits, len(field), resolvedType, f.Name)
+	}
+
+	padding := len(field) - len(digits)
+	if f.numeric.left {
+		copy(field, digits)
+		for i := len(digits); i < len(field); i++ {
+			field[i] = f.numeric.pad
+		}
+		return f.Length, nil
+	}
+	for i := 0; i < padding; i++ {
+		field[i] = f.numeric.pad
+	}
+	copy(field[padding:], digits)
+	// 以 '0' 填充的负数把符号放在最前面，如 -0012
+	if negative && f.numeric.pad == '0' && padding > 0 {
+		field[0], field[padding] = '-', '0'
+	}
+	return f.Length, nil
+}
+
+// packBCD 将十进制数写入压缩 BCD
+func (f *Field) packBCD(buffer []byte, value uint64) error {
+	digits := strconv.FormatUint(value, 10)
+	nibbles := len(buffer) * 2
+	if len(digits) > nibbles {
+		return fmt.Errorf("struc: %s does not fit in %d bcd digits (field `%s`)", digits, nibbles, f.Name)
+	}
+
+	for i := 0; i < nibbles; i++ {
+		var nibble byte
+		if f.numeric.left {
+			nibble = 0x0f
+			if i < len(digits) {
+				nibble = digits[i] - '0'
+			}
+		} else if i >= nibbles-len(digits) {
+			nibble = digits[i-(nibbles-len(digits))] - '0'
+		}
+		if i%2 == 0 {
+			buffer[i/2] = nibble << 4
+		} else {
+			buffer[i/2] |= nibble
+		}
+	}
+	return nil
+}
+
+// unpackNumeric 解包 BCD 或 ASCII 数字字段
+func (f *Field) unpackNumeric(buffer []byte, fieldValue reflect.Value, resolvedType Type) error {
+	if f.IsPointer {
+		fieldValue = fieldValue.Elem()
+	}
+
+	var value uint64
+	var negative bool
+	var err error
+	if resolvedType == BCD {
+		value, err = f.unpackBCD(buffer[:f.Length])
+	} else {
+		value, negative, err = f.parseNumericText(buffer[:f.Length], resolvedType)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		signed := int64(value)
+		if negative {
+			signed = -signed
+		}
+		if ((signed < 0) != negative && value != 0) || fieldValue.OverflowInt(signed) {
+			return fmt.Errorf("struc: %s value %d overflows %v (field `%s`)", resolvedType, value, fieldValue.Type(), f.Name)
+		}
+		fieldValue.SetInt(signed)
+	default:
+		if negative && value != 0 {
+			return fmt.Errorf("struc: negative %s value for unsigned field `%s`", resolvedType, f.Name)
+		}
+		if fieldValue.OverflowUint(value) {
+			return fmt.Errorf("struc: %s value %d overflows %v (field `%s`)", resolvedType, value, fieldValue.Type(), f.Name)
+		}
+		fieldValue.SetUint(value)
+	}
+	return nil
+}
+
+// unpackBCD 读取压缩 BCD，末尾的 F 半字节视为填充
+func (f *Field) unpackBCD(buffer []byte) (uint64, error) {
+	var value uint64
+	padded := false
+	for i := 0; i < len(buffer)*2; i++ {
+		nibble := buffer[i/2] >> 4
+		if i%2 == 1 {
+			nibble = buffer[i/2] & 0x0f
+		}
+		if nibble == 0x0f {
+			padded = true
+			continue
+		}
+		if nibble > 9 || padded {
+			return 0, fmt.Errorf("struc: invalid bcd digit %X in % X (field `%s`)", nibble, buffer, f.Name)
+		}
+		if value > (1<<64-1-uint64(nibble))/10 {
+			return 0, fmt.Errorf("struc: bcd value % X overflows uint64 (field `%s`)", buffer, f.Name)
+		}
+		value = value*10 + uint64(nibble)
+	}
+	return value, nil
+}
+
+// parseNumericText 读取 ASCII 数字，忽略两端的空格和 NUL
+func (f *Field) parseNumericText(buffer []byte, resolvedType Type) (uint64, bool, error) {
+	text := bytes.Trim(buffer, " \x00")
+	negative := false
+	if resolvedType == Decimal && len(text) > 0 && text[0] == '-' {
+		negative = true
+		text = text[1:]
+	}
+	if len(text) == 0 {
+		return 0, negative, nil
+	}
+	for _, c := range text {
+		if digitValue(c) >= f.numeric.base {
+			return 0, false, fmt.Errorf("struc: invalid %s digit %q in %q (field `%s`)", resolvedType, c, buffer, f.Name)
+		}
+	}
+	value, err := strconv.ParseUint(string(text), f.numeric.base, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("struc: %s value %q overflows uint64 (field `%s`)", resolvedType, text, f.Name)
+	}
+	return value, negative, nil
+}
+
+// digitValue 返回 ASCII 字符作为数字的值，非数字字符返回 36
+func digitValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	default:
+		return 36
+	}
+}
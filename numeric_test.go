@@ -0,0 +1,155 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// numericTarHeader 对应 tar、ar 和 cpio 头部中的数字字段
+type numericTarHeader struct {
+	Mode  uint32 `struc:"octal=8,term=nul"`
+	Size  int64  `struc:"octal=12,term=space"`
+	Date  int    `struc:"decimal=12,justify=left"`
+	Delta int16  `struc:"decimal=6"`
+	Inode uint32 `struc:"hexascii=8"`
+}
+
+// numericEMV 对应 EMV 报文中的 BCD 字段
+type numericEMV struct {
+	Amount   uint64  `struc:"[6]bcd"`
+	Currency uint16  `struc:"bcd"`
+	PAN      uint64  `struc:"[10]bcd,justify=left"`
+	Counter  *uint32 `struc:"[2]bcd"`
+}
+
+func TestNumericText(t *testing.T) {
+	in := &numericTarHeader{Mode: 0644, Size: 1024, Date: 1700000000, Delta: -12, Inode: 0xbeef}
+	want := []byte("0000644\x00" + "00000002000 " + "1700000000  " + "-00012" + "0000BEEF")
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %q, want %q", buf.Bytes(), want)
+	}
+	if format, err := GetFormatString(in); err != nil || format != ">8s12s12s6s8s" {
+		t.Fatalf("format: got %q (%v)", format, err)
+	}
+
+	out := &numericTarHeader{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+
+	// 解包时接受空格、NUL 填充和小写十六进制
+	loose := []byte("   644\x00\x00" + "       2000\x00" + "1700000000\x00\x00" + "   -12" + "0000beef")
+	out = &numericTarHeader{}
+	if err := Unpack(bytes.NewReader(loose), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("loose: got %+v, want %+v", out, in)
+	}
+}
+
+func TestNumericBCD(t *testing.T) {
+	counter := uint32(42)
+	in := &numericEMV{Amount: 123456, Currency: 978, PAN: 4111111111111111, Counter: &counter}
+	want := []byte{
+		0x00, 0x00, 0x00, 0x12, 0x34, 0x56,
+		0x00, 0x09, 0x78,
+		0x41, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0xff, 0xff,
+		0x00, 0x42,
+	}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got % x, want % x", buf.Bytes(), want)
+	}
+
+	out := &numericEMV{Counter: new(uint32)}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestNumericErrors(t *testing.T) {
+	packTests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{"octal too wide", &struct {
+			V uint32 `struc:"octal=3"`
+		}{01000}, "does not fit in 3 octal digits"},
+		{"bcd too wide", &struct {
+			V uint32 `struc:"[1]bcd"`
+		}{100}, "does not fit in 2 bcd digits"},
+		{"negative octal", &struct {
+			V int32 `struc:"octal=4"`
+		}{-1}, "negative value"},
+		{"string field", &struct {
+			V string `struc:"decimal=4"`
+		}{}, "must be an integer"},
+		{"left zero padding", &struct {
+			V int `struc:"decimal=4,justify=left,pad=zero"`
+		}{}, "cannot be padded with zeros"},
+		{"bcd padding", &struct {
+			V int `struc:"bcd,pad=space"`
+		}{}, "does not support"},
+		{"padding on plain field", &struct {
+			V int32 `struc:"int32,pad=space"`
+		}{}, "only apply to"},
+	}
+	for _, tt := range packTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Pack(&bytes.Buffer{}, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	unpackTests := []struct {
+		name string
+		data interface{}
+		in   []byte
+		want string
+	}{
+		{"octal digit", &struct {
+			V uint32 `struc:"octal=4"`
+		}{}, []byte("0089"), "invalid octal digit '8'"},
+		{"decimal letter", &struct {
+			V uint32 `struc:"decimal=4"`
+		}{}, []byte("12a4"), "invalid decimal digit 'a'"},
+		{"bcd nibble", &struct {
+			V uint32 `struc:"[2]bcd"`
+		}{}, []byte{0x12, 0x3a}, "invalid bcd digit A"},
+		{"overflow", &struct {
+			V uint8 `struc:"decimal=4"`
+		}{}, []byte("0300"), "overflows uint8"},
+		{"negative unsigned", &struct {
+			V uint8 `struc:"decimal=4"`
+		}{}, []byte("  -1"), "negative decimal value"},
+	}
+	for _, tt := range unpackTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unpack(bytes.NewReader(tt.in), tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
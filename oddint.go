@@ -0,0 +1,203 @@
+package struc
+
+import (
+	"encoding/binary"
+	"reflect"
+	"unsafe"
+)
+
+// putUintN 按指定宽度（1~8 字节）写入无符号整数
+// 字节序为 nil 时按大端序处理，与其他整数类型保持一致
+func putUintN(buffer []byte, value uint64, size int, byteOrder binary.ByteOrder) {
+	_ = buffer[size-1]
+	if byteOrder == binary.LittleEndian {
+		for i := 0; i < size; i++ {
+			buffer[i] = byte(value >> (8 * i))
+		}
+		return
+	}
+	for i := size - 1; i >= 0; i-- {
+		buffer[i] = byte(value)
+		value >>= 8
+	}
+}
+
+// getUintN 按指定宽度（1~8 字节）读取无符号整数
+func getUintN(buffer []byte, size int, byteOrder binary.ByteOrder) uint64 {
+	_ = buffer[size-1]
+	var value uint64
+	if byteOrder == binary.LittleEndian {
+		for i := size - 1; i >= 0; i-- {
+			value = value<<8 | uint64(buffer[i])
+		}
+		return value
+	}
+	for i := 0; i < size; i++ {
+		value = value<<8 | uint64(buffer[i])
+	}
+	return value
+}
+
+// signExtend 将 size 字节宽的补码整数符号扩展为 64 位
+func signExtend(value uint64, size int) uint64 {
+	shift := uint(64 - size*8)
+	return uint64(int64(value<<shift) >> shift)
+}
+
+// readOddWidthInteger 读取非 2 的幂宽度的整数，有符号类型进行符号扩展
+func readOddWidthInteger(buffer []byte, resolvedType Type, byteOrder binary.ByteOrder) uint64 {
+	size := resolvedType.Size()
+	value := getUintN(buffer, size, byteOrder)
+	switch resolvedType {
+	case Int24, Int40, Int48, Int56:
+		return signExtend(value, size)
+	}
+	return value
+}
+
+// packOddWidthSlice 打包非 2 的幂宽度整数的切片或数组
+// 常见的 Go 元素类型直接通过 unsafe 访问底层数组，避免逐元素 reflect
+func (f *Field) packOddWidthSlice(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, byteOrder binary.ByteOrder) int {
+	elementSize := resolvedType.Size()
+	totalSize := length * elementSize
+	dataLength := fieldValue.Len()
+	if dataLength > length {
+		dataLength = length
+	}
+
+	if dataLength > 0 && !f.packOddWidthFast(buffer, fieldValue, dataLength, elementSize, byteOrder) {
+		for i := 0; i < dataLength; i++ {
+			putUintN(buffer[i*elementSize:], f.getIntegerValue(fieldValue.Index(i)), elementSize, byteOrder)
+		}
+	}
+	if dataLength < length {
+		memclr(buffer[dataLength*elementSize : totalSize])
+	}
+	return totalSize
+}
+
+// packOddWidthFast 针对常见元素类型的快速打包路径
+// 无法直接取得底层数组时返回 false，由调用方回退到 reflect
+func (f *Field) packOddWidthFast(buffer []byte, fieldValue reflect.Value, count, elementSize int, byteOrder binary.ByteOrder) bool {
+	ptr, ok := sliceDataPointer(fieldValue)
+	if !ok {
+		return false
+	}
+	switch fieldValue.Type().Elem().Kind() {
+	case reflect.Int32:
+		for i, v := range unsafe.Slice((*int32)(ptr), count) {
+			putUintN(buffer[i*elementSize:], uint64(v), elementSize, byteOrder)
+		}
+	case reflect.Uint32:
+		for i, v := range unsafe.Slice((*uint32)(ptr), count) {
+			putUintN(buffer[i*elementSize:], uint64(v), elementSize, byteOrder)
+		}
+	case reflect.Int64:
+		for i, v := range unsafe.Slice((*int64)(ptr), count) {
+			putUintN(buffer[i*elementSize:], uint64(v), elementSize, byteOrder)
+		}
+	case reflect.Uint64:
+		for i, v := range unsafe.Slice((*uint64)(ptr), count) {
+			putUintN(buffer[i*elementSize:], v, elementSize, byteOrder)
+		}
+	case reflect.Int:
+		for i, v := range unsafe.Slice((*int)(ptr), count) {
+			putUintN(buffer[i*elementSize:], uint64(v), elementSize, byteOrder)
+		}
+	case reflect.Uint:
+		for i, v := range unsafe.Slice((*uint)(ptr), count) {
+			putUintN(buffer[i*elementSize:], uint64(v), elementSize, byteOrder)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// unpackOddWidthSlice 解包非 2 的幂宽度整数的切片或数组
+func (f *Field) unpackOddWidthSlice(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, byteOrder binary.ByteOrder) {
+	if !f.IsArray {
+		if fieldValue.Cap() < length {
+			fieldValue.Set(reflect.MakeSlice(fieldValue.Type(), length, length))
+		} else if fieldValue.Len() != length {
+			fieldValue.Set(fieldValue.Slice(0, length))
+		}
+	}
+	if length > fieldValue.Len() {
+		length = fieldValue.Len()
+	}
+	if length == 0 || f.unpackOddWidthFast(buffer, fieldValue, length, resolvedType, byteOrder) {
+		return
+	}
+
+	elementSize := resolvedType.Size()
+	for i := 0; i < length; i++ {
+		value := readOddWidthInteger(buffer[i*elementSize:], resolvedType, byteOrder)
+		elem := fieldValue.Index(i)
+		switch elem.Kind() {
+		case reflect.Bool:
+			elem.SetBool(value != 0)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			elem.SetInt(int64(value))
+		default:
+			elem.SetUint(value)
+		}
+	}
+}
+
+// unpackOddWidthFast 针对常见元素类型的快速解包路径
+func (f *Field) unpackOddWidthFast(buffer []byte, fieldValue reflect.Value, count int, resolvedType Type, byteOrder binary.ByteOrder) bool {
+	ptr, ok := sliceDataPointer(fieldValue)
+	if !ok {
+		return false
+	}
+	elementSize := resolvedType.Size()
+	switch fieldValue.Type().Elem().Kind() {
+	case reflect.Int32:
+		dst := unsafe.Slice((*int32)(ptr), count)
+		for i := range dst {
+			dst[i] = int32(readOddWidthInteger(buffer[i*elementSize:], resolvedType, byteOrder))
+		}
+	case reflect.Uint32:
+		dst := unsafe.Slice((*uint32)(ptr), count)
+		for i := range dst {
+			dst[i] = uint32(readOddWidthInteger(buffer[i*elementSize:], resolvedType, byteOrder))
+		}
+	case reflect.Int64:
+		dst := unsafe.Slice((*int64)(ptr), count)
+		for i := range dst {
+			dst[i] = int64(readOddWidthInteger(buffer[i*elementSize:], resolvedType, byteOrder))
+		}
+	case reflect.Uint64:
+		dst := unsafe.Slice((*uint64)(ptr), count)
+		for i := range dst {
+			dst[i] = readOddWidthInteger(buffer[i*elementSize:], resolvedType, byteOrder)
+		}
+	case reflect.Int:
+		dst := unsafe.Slice((*int)(ptr), count)
+		for i := range dst {
+			dst[i] = int(readOddWidthInteger(buffer[i*elementSize:], resolvedType, byteOrder))
+		}
+	case reflect.Uint:
+		dst := unsafe.Slice((*uint)(ptr), count)
+		for i := range dst {
+			dst[i] = uint(readOddWidthInteger(buffer[i*elementSize:], resolvedType, byteOrder))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// sliceDataPointer 获取切片或可寻址数组的底层数据指针
+func sliceDataPointer(fieldValue reflect.Value) (unsafe.Pointer, bool) {
+	switch fieldValue.Kind() {
+	case reflect.Slice:
+		return unsafe.Pointer(fieldValue.Pointer()), true
+	case reflect.Array:
+		if fieldValue.CanAddr() {
+			return unsafe.Pointer(fieldValue.UnsafeAddr()), true
+		}
+	}
+	return nil, false
+}
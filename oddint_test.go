@@ -0,0 +1,128 @@
+package struc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+type oddIntExample struct {
+	A int32   `struc:"int24"`
+	B uint32  `struc:"uint24,little"`
+	C int64   `struc:"int40"`
+	D uint64  `struc:"uint48"`
+	E int64   `struc:"int56,little"`
+	F []int32 `struc:"[2]int24"`
+	G [3]uint `struc:"[3]uint24,little"`
+}
+
+func TestOddWidthIntegers(t *testing.T) {
+	var buf bytes.Buffer
+	in := &oddIntExample{
+		A: -2,
+		B: 0x123456,
+		C: -1 << 39,
+		D: 0xa1b2c3d4e5f6,
+		E: -3,
+		F: []int32{-8388608, 8388607},
+		G: [3]uint{1, 0xfffffe, 0x10203},
+	}
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0xff, 0xff, 0xfe,
+		0x56, 0x34, 0x12,
+		0x80, 0x00, 0x00, 0x00, 0x00,
+		0xa1, 0xb2, 0xc3, 0xd4, 0xe5, 0xf6,
+		0xfd, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0x80, 0x00, 0x00, 0x7f, 0xff, 0xff,
+		0x01, 0x00, 0x00, 0xfe, 0xff, 0xff, 0x03, 0x02, 0x01,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("odd width pack: got %x, want %x", buf.Bytes(), want)
+	}
+	if size, err := Sizeof(in); err != nil || size != len(want) {
+		t.Fatalf("odd width sizeof: got %d (%v), want %d", size, err, len(want))
+	}
+
+	out := &oddIntExample{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("odd width round trip: got %+v, want %+v", out, in)
+	}
+}
+
+type oddIntSlice struct {
+	Count int `struc:"uint8,sizeof=Samples"`
+	Pad   [2]byte
+	// int16 元素不在快速路径中，走 reflect 回退
+	Sizes   []int16 `struc:"[2]int24"`
+	Samples []int32 `struc:"[]int24"`
+}
+
+func TestOddWidthSliceOptionsOrder(t *testing.T) {
+	in := &oddIntSlice{Sizes: []int16{-1, 300}, Samples: []int32{1, -1, 0x7fffff}}
+	var buf bytes.Buffer
+	if err := PackWithOptions(&buf, in, &Options{Order: binary.LittleEndian}); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		3, 0, 0,
+		0xff, 0xff, 0xff, 0x2c, 0x01, 0x00,
+		0x01, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("odd width little endian: got %x, want %x", buf.Bytes(), want)
+	}
+
+	out := &oddIntSlice{}
+	if err := UnpackWithOptions(bytes.NewReader(want), out, &Options{Order: binary.LittleEndian}); err != nil {
+		t.Fatal(err)
+	}
+	in.Count = 3
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("odd width round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestOddWidthBool(t *testing.T) {
+	type flags struct {
+		B     bool    `struc:"uint24"`
+		Flags []bool  `struc:"[2]uint40"`
+		Array [2]bool `struc:"[2]int56,little"`
+	}
+	in := &flags{B: true, Flags: []bool{false, true}, Array: [2]bool{true, false}}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("odd width bool pack: got %x, want %x", buf.Bytes(), want)
+	}
+	out := &flags{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("odd width bool round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestOddWidthFormatString(t *testing.T) {
+	format, err := GetFormatString(&oddIntExample{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ">3s<3s>5s6s<7s>3s3s<3s3s3s"; format != want {
+		t.Fatalf("odd width format: got %q, want %q", format, want)
+	}
+}
@@ -0,0 +1,231 @@
+package struc
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// offsetSource 是解包偏移字段所需的随机访问数据源
+// *bytes.Reader、*os.File 和 *io.SectionReader 均满足该接口
+type offsetSource interface {
+	io.ReaderAt
+	io.Seeker
+}
+
+// handleOffsetTag 处理 offsetfrom= 和 offsetof= 标签
+// offsetfrom=Field 表示该字段不按顺序存放，而是位于 Field 中保存的偏移处；
+// offsetof=Field 表示该字段保存 Field 的偏移，打包时自动计算。
+// 两种写法等价，只需在任意一侧声明；偏移相对于所在结构体的起始位置
+func handleOffsetTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField) error {
+	if fieldTag.Offsetfrom != "" {
+		source, ok := structType.FieldByName(fieldTag.Offsetfrom)
+		if !ok {
+			return fmt.Errorf("struc: `offsetfrom=%s` field does not exist", fieldTag.Offsetfrom)
+		}
+		if fieldDesc.BitSize > 0 {
+			return fmt.Errorf("struc: bitfield `%s` cannot be stored at an offset", field.Name)
+		}
+		fieldDesc.Offsetfrom = source.Index
+	}
+
+	if fieldTag.Offsetof != "" {
+		target, ok := structType.FieldByName(fieldTag.Offsetof)
+		if !ok {
+			return fmt.Errorf("struc: `offsetof=%s` field does not exist", fieldTag.Offsetof)
+		}
+		if target.Index[0] == field.Index[0] {
+			return fmt.Errorf("struc: `offsetof=%s` cannot refer to the field itself", fieldTag.Offsetof)
+		}
+		fieldDesc.offsetFor = target.Index
+	}
+	return nil
+}
+
+// linkOffsetFields 在所有字段解析完成后关联偏移字段与其数据字段，并校验两者的约束
+func linkOffsetFields(fields Fields) error {
+	for _, field := range fields {
+		if field == nil || field.offsetFor == nil {
+			continue
+		}
+		target := fields[field.offsetFor[0]]
+		if target == nil {
+			return fmt.Errorf("struc: `offsetof` target of field `%s` is skipped", field.Name)
+		}
+		if target.Offsetfrom != nil && target.Offsetfrom[0] != field.Index {
+			return fmt.Errorf("struc: field `%s` has conflicting offset fields", target.Name)
+		}
+		if target.BitSize > 0 {
+			return fmt.Errorf("struc: bitfield `%s` cannot be stored at an offset", target.Name)
+		}
+		target.Offsetfrom = []int{field.Index}
+	}
+
+	for _, field := range fields {
+		if field == nil || field.Offsetfrom == nil {
+			continue
+		}
+		source := fields[field.Offsetfrom[0]]
+		if source == nil {
+			return fmt.Errorf("struc: offset field of `%s` is skipped", field.Name)
+		}
+		switch source.Type {
+		case Int8, Int16, Int32, Int64, Uint8, Uint16, Uint32, Uint64:
+		default:
+			return fmt.Errorf("struc: offset field `%s` must be a fixed-size integer", source.Name)
+		}
+		if source.IsSlice || source.IsPointer || source.BitSize > 0 || source.Offsetfrom != nil ||
+			source.condition != nil || source.isOptional() {
+			return fmt.Errorf("struc: offset field `%s` must be a plain integer stored in line", source.Name)
+		}
+		if field.Align > 1 {
+			return fmt.Errorf("struc: field `%s` stored at an offset cannot be aligned", field.Name)
+		}
+		if source.offsetFor != nil && source.offsetFor[0] != field.Index {
+			return fmt.Errorf("struc: offset field `%s` is shared by several fields", source.Name)
+		}
+		source.offsetFor = []int{field.Index}
+	}
+	return nil
+}
+
+// hasOffsets 判断字段集合中是否有按偏移存放的字段
+func (f Fields) hasOffsets() bool {
+	for _, field := range f {
+		if field != nil && field.Offsetfrom != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldSizeof 返回单个字段打包后占用的字节数
+func (f Fields) fieldSizeof(structValue reflect.Value, i int, field *Field, options *Options) int {
+	// 条件不成立的字段不占用空间；求值失败时由 Pack 报告错误
+	if present, err := field.present(structValue); err != nil || !present {
+		return 0
+	}
+	size := 0
+	// 可选字段为 nil 时只占用内联存在标记
+	if field.isOptional() {
+		size += field.presenceSize()
+		if structValue.Field(i).IsNil() {
+			return size
+		}
+	}
+	// 常量字段总是按常量值打包
+	if field.constValue.IsValid() {
+		return size + field.Size(field.constValue, options)
+	}
+	// 变长整数作为 sizeof 字段时，其宽度取决于打包时写入的长度值，而非字段当前值
+	if (field.Sizeof != nil || field.Bytesof != nil) && field.Type.IsVarint() {
+		value, _ := f.sizeofValue(structValue, field, options)
+		return size + field.alignSize(varintSize(field.Type, uint64(value)), options)
+	}
+	if field.scoped {
+		options = options.enter(structValue)
+	}
+	return size + field.Size(structValue.Field(i), options)
+}
+
+// layoutOffsets 布局阶段：按字段顺序将偏移字段引用的数据依次放在固定部分之后，
+// 并把计算出的偏移写入对应的偏移字段
+func (f Fields) layoutOffsets(structValue reflect.Value, options *Options) error {
+	position := f.fixedSizeof(structValue, options)
+	for i, field := range f {
+		if field == nil || field.Offsetfrom == nil {
+			continue
+		}
+		offsetValue := structValue.Field(field.Offsetfrom[0])
+		switch offsetValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if offsetValue.OverflowInt(int64(position)) {
+				return fmt.Errorf("struc: offset %d of field `%s` overflows %v", position, field.Name, offsetValue.Type())
+			}
+			offsetValue.SetInt(int64(position))
+		default:
+			if offsetValue.OverflowUint(uint64(position)) {
+				return fmt.Errorf("struc: offset %d of field `%s` overflows %v", position, field.Name, offsetValue.Type())
+			}
+			offsetValue.SetUint(uint64(position))
+		}
+		position += f.fieldSizeof(structValue, i, field, options)
+	}
+	return nil
+}
+
+// packOutOfLine 在固定部分之后依次写入按偏移存放的字段
+func (f Fields) packOutOfLine(buffer []byte, structValue reflect.Value, options *Options) (int, error) {
+	position := 0
+	for i, field := range f {
+		if field == nil || field.Offsetfrom == nil {
+			continue
+		}
+		n, err := f.packField(buffer[position:], structValue, i, field, options)
+		if err != nil {
+			return position, err
+		}
+		position += n
+	}
+	return position, nil
+}
+
+// offsetSourceOf 返回解包偏移字段所用的数据源以及结构体起始位置
+func offsetSourceOf(reader io.Reader) (offsetSource, int64, error) {
+	source, ok := reader.(offsetSource)
+	if !ok {
+		return nil, 0, fmt.Errorf("struc: offset fields require an io.ReaderAt and io.Seeker source such as *bytes.Reader, got %T", reader)
+	}
+	base, err := source.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+	return source, base, nil
+}
+
+// unpackOutOfLine 按偏移字段中保存的位置读取数据
+// 读取完成后数据源定位到已读取数据的末尾（不早于固定部分的末尾），便于外层结构体继续顺序解包
+func (f Fields) unpackOutOfLine(source offsetSource, base int64, structValue reflect.Value, options *Options, scratch *scratchArena) error {
+	end, err := source.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	for i, field := range f {
+		if field == nil || field.Offsetfrom == nil {
+			continue
+		}
+		fieldValue := structValue.Field(i)
+
+		present, err := field.present(structValue)
+		if err != nil {
+			return err
+		}
+		if !present {
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			continue
+		}
+
+		offset := f.sizefrom(structValue, field.Offsetfrom)
+		if offset < 0 {
+			return fmt.Errorf("struc: negative offset %d for field `%s`", offset, field.Name)
+		}
+		start := base + int64(offset)
+		section := io.NewSectionReader(source, start, math.MaxInt64-start)
+		if err := f.unpackField(section, structValue, fieldValue, field, options, scratch); err != nil {
+			return err
+		}
+
+		read, err := section.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if start+read > end {
+			end = start + read
+		}
+	}
+
+	_, err = source.Seek(end, io.SeekStart)
+	return err
+}
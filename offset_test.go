@@ -0,0 +1,132 @@
+package struc
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type offsetBlob struct {
+	Kind  uint8
+	Value uint16
+}
+
+type offsetArchive struct {
+	Magic   [4]byte
+	NameOff uint16 `struc:"uint16,offsetof=Name"`
+	NameLen uint8  `struc:"uint8,sizeof=Name"`
+	DataOff uint32
+	Name    []byte
+	Data    offsetBlob `struc:"offsetfrom=DataOff"`
+}
+
+func TestOffsetFields(t *testing.T) {
+	in := &offsetArchive{Magic: [4]byte{'A', 'R', 'C', '1'}, Name: []byte("hello"), Data: offsetBlob{Kind: 7, Value: 0x0102}}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		'A', 'R', 'C', '1',
+		0, 11,
+		5,
+		0, 0, 0, 16,
+		'h', 'e', 'l', 'l', 'o',
+		7, 1, 2,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %v, want %v", buf.Bytes(), want)
+	}
+	if in.NameOff != 11 || in.DataOff != 16 {
+		t.Fatalf("offsets not written back: %+v", in)
+	}
+	if size, err := Sizeof(in); err != nil || size != len(want) {
+		t.Fatalf("sizeof: got %d (%v), want %d", size, err, len(want))
+	}
+
+	reader := bytes.NewReader(want)
+	out := &offsetArchive{}
+	if err := Unpack(reader, out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+	if reader.Len() != 0 {
+		t.Fatalf("reader should be positioned after the out-of-line data, %d bytes left", reader.Len())
+	}
+}
+
+func TestOffsetFieldsArbitraryLayout(t *testing.T) {
+	// 数据顺序与字段顺序相反，且之间留有空隙；偏移相对于结构体的起始位置
+	data := []byte{
+		0xee, 0xee, // 前置数据，不属于结构体
+		'A', 'R', 'C', '1',
+		0, 16,
+		3,
+		0, 0, 0, 11,
+		9, 0, 1,
+		0xff, 0xff,
+		'a', 'b', 'c',
+		0xaa,
+	}
+	reader := bytes.NewReader(data)
+	if _, err := reader.Seek(2, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	out := &offsetArchive{}
+	if err := Unpack(reader, out); err != nil {
+		t.Fatal(err)
+	}
+	if string(out.Name) != "abc" || out.Data != (offsetBlob{Kind: 9, Value: 1}) {
+		t.Fatalf("unpack: got %+v", out)
+	}
+	if reader.Len() != 1 {
+		t.Fatalf("reader position: %d bytes left, want 1", reader.Len())
+	}
+}
+
+func TestOffsetFieldErrors(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 19))
+	err := Unpack(&buf, &offsetArchive{})
+	if err == nil || !strings.Contains(err.Error(), "io.ReaderAt") {
+		t.Fatalf("expected source error, got %v", err)
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"missing", &struct {
+			Off  uint8 `struc:"uint8,offsetof=Data"`
+			Blob uint8
+		}{}, "does not exist"},
+		{"not integer", &struct {
+			Off  float32
+			Data uint8 `struc:"uint8,offsetfrom=Off"`
+		}{}, "fixed-size integer"},
+		{"shared", &struct {
+			Off uint8
+			A   uint8 `struc:"uint8,offsetfrom=Off"`
+			B   uint8 `struc:"uint8,offsetfrom=Off"`
+		}{}, "shared"},
+		{"overflow", &struct {
+			Off  uint8 `struc:"uint8,offsetof=Data"`
+			Pad  [300]byte
+			Data uint8
+		}{}, "overflows"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Pack(&buf, tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
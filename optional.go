@@ -0,0 +1,115 @@
+package struc
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// handleOptionalTag 处理可选指针字段的 optional= 和 presence= 标签
+// optional=Field 由之前的 bool 或 bits=1 字段标记是否存在，每个标记字段只能对应一个可选字段；
+// optional,presence=uint8 在字段内容之前内联写入一个存在标记
+func handleOptionalTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField, fields Fields) error {
+	if !fieldTag.Optional {
+		if fieldTag.Presence != "" {
+			return fmt.Errorf("struc: `presence=%s` requires `optional` (field `%s`)", fieldTag.Presence, field.Name)
+		}
+		return nil
+	}
+	if !fieldDesc.IsPointer {
+		return fmt.Errorf("struc: optional field `%s` must be a pointer", field.Name)
+	}
+	if fieldDesc.Sizeof != nil || fieldDesc.BitSize > 0 {
+		return fmt.Errorf("struc: optional field `%s` cannot be a sizeof field or bitfield", field.Name)
+	}
+
+	switch {
+	case fieldTag.OptionalFrom != "" && fieldTag.Presence != "":
+		return fmt.Errorf("struc: optional field `%s` cannot use both a presence field and an inline marker", field.Name)
+	case fieldTag.Presence != "":
+		presenceType := typeStrToType[fieldTag.Presence]
+		switch presenceType {
+		case Bool, Int8, Int16, Int32, Int64, Uint8, Uint16, Uint32, Uint64:
+			fieldDesc.Presence = presenceType
+			return nil
+		default:
+			return fmt.Errorf("struc: invalid presence type `%s` (field `%s`)", fieldTag.Presence, field.Name)
+		}
+	case fieldTag.OptionalFrom == "":
+		return fmt.Errorf("struc: optional field `%s` requires `optional=Field` or `presence=type`", field.Name)
+	}
+
+	flagField, ok := structType.FieldByName(fieldTag.OptionalFrom)
+	if !ok {
+		return fmt.Errorf("struc: `optional=%s` field does not exist", fieldTag.OptionalFrom)
+	}
+	if flagField.Index[0] >= field.Index[0] {
+		return fmt.Errorf("struc: `optional=%s` must precede field `%s`", fieldTag.OptionalFrom, field.Name)
+	}
+	flag := fields[flagField.Index[0]]
+	if flag == nil {
+		return fmt.Errorf("struc: `optional=%s` field is skipped", fieldTag.OptionalFrom)
+	}
+	// 打包时标记字段被整体写为 0 或 1，多位整数中的其他位会丢失
+	if flagField.Type.Kind() != reflect.Bool && flag.BitSize != 1 {
+		return fmt.Errorf("struc: `optional=%s` must be a bool or `bits=1` field", fieldTag.OptionalFrom)
+	}
+	if flag.optionalFor != nil {
+		return fmt.Errorf("struc: `optional=%s` already marks the presence of another field (field `%s`)", fieldTag.OptionalFrom, field.Name)
+	}
+	flag.optionalFor = field.Index
+	fieldDesc.Optional = flagField.Index
+	return nil
+}
+
+// isOptional 判断字段是否为可选字段
+func (f *Field) isOptional() bool {
+	return f.Optional != nil || f.Presence != Invalid
+}
+
+// presenceSize 返回内联存在标记占用的字节数
+func (f *Field) presenceSize() int {
+	if f.Presence == Invalid {
+		return 0
+	}
+	return f.Presence.Size()
+}
+
+// setPresenceFlag 根据可选字段是否为 nil 设置存在标记字段
+func (f Fields) setPresenceFlag(structValue reflect.Value, fieldValue reflect.Value, field *Field) {
+	present := !structValue.FieldByIndex(field.optionalFor).IsNil()
+	switch fieldValue.Kind() {
+	case reflect.Bool:
+		fieldValue.SetBool(present)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fieldValue.SetInt(boolToInt64(present))
+	default:
+		fieldValue.SetUint(uint64(boolToInt64(present)))
+	}
+}
+
+// packPresence 写入内联存在标记
+func (f *Field) packPresence(buffer []byte, present bool, options *Options) (int, error) {
+	if err := f.writeInteger(buffer, uint64(boolToInt64(present)), f.Presence, f.determineByteOrder(options)); err != nil {
+		return 0, err
+	}
+	return f.Presence.Size(), nil
+}
+
+// unpackPresence 判断可选字段在输入中是否存在
+// 内联标记从 reader 读取，否则读取之前已解包的标记字段
+func (f *Field) unpackPresence(reader io.Reader, structValue reflect.Value, options *Options, scratch *scratchArena) (bool, error) {
+	if f.Presence == Invalid {
+		flag := structValue.FieldByIndex(f.Optional)
+		if flag.Kind() == reflect.Bool {
+			return flag.Bool(), nil
+		}
+		return !flag.IsZero(), nil
+	}
+
+	buffer := scratch.Get(f.Presence.Size())
+	if _, err := io.ReadFull(reader, buffer); err != nil {
+		return false, err
+	}
+	return f.readInteger(buffer, f.Presence, f.determineByteOrder(options)) != 0, nil
+}
@@ -0,0 +1,136 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type optionalExt struct {
+	A uint16
+	B uint8
+}
+
+type optionalFrame struct {
+	HasExt   bool
+	Reserved uint8        `struc:"uint8,bits=7"`
+	HasID    uint8        `struc:"uint8,bits=1"`
+	Ext      *optionalExt `struc:"optional=HasExt"`
+	ID       *uint32      `struc:"uint32,optional=HasID"`
+	Note     *uint16      `struc:"uint16,optional,presence=uint8"`
+	Tail     uint8
+}
+
+func TestOptionalFields(t *testing.T) {
+	id := uint32(0x01020304)
+	note := uint16(0xbeef)
+	tests := []struct {
+		name string
+		in   optionalFrame
+		want []byte
+	}{
+		{
+			name: "present",
+			in:   optionalFrame{Ext: &optionalExt{A: 0x0a0b, B: 0x0c}, ID: &id, Note: &note, Tail: 9},
+			want: []byte{1, 0x01, 0x0a, 0x0b, 0x0c, 1, 2, 3, 4, 1, 0xbe, 0xef, 9},
+		},
+		{
+			name: "absent",
+			in:   optionalFrame{HasExt: true, HasID: 1, Tail: 9},
+			want: []byte{0, 0x00, 0, 9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.in
+			var buf bytes.Buffer
+			if err := Pack(&buf, &in); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Fatalf("pack: got %x, want %x", buf.Bytes(), tt.want)
+			}
+			if size, err := Sizeof(&in); err != nil || size != len(tt.want) {
+				t.Fatalf("sizeof: got %d (%v), want %d", size, err, len(tt.want))
+			}
+			// 存在标记应已根据指针是否为 nil 自动设置
+			if in.HasExt != (in.Ext != nil) || (in.HasID == 1) != (in.ID != nil) {
+				t.Fatalf("presence flags not updated: %+v", in)
+			}
+
+			out := optionalFrame{Ext: &optionalExt{}, ID: new(uint32), Note: new(uint16)}
+			if err := Unpack(bytes.NewReader(tt.want), &out); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(in, out) {
+				t.Fatalf("round trip: got %+v, want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestOptionalFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"not pointer", &struct {
+			Has bool
+			V   uint8 `struc:"uint8,optional=Has"`
+		}{}, "must be a pointer"},
+		{"no source", &struct {
+			V *uint8 `struc:"uint8,optional"`
+		}{}, "requires `optional=Field`"},
+		{"bad presence", &struct {
+			V *uint8 `struc:"uint8,optional,presence=float32"`
+		}{}, "invalid presence type"},
+		{"multi-bit flag", &struct {
+			Flags uint8  `struc:"uint8"`
+			V     *uint8 `struc:"uint8,optional=Flags"`
+		}{}, "must be a bool or `bits=1` field"},
+		{"shared flag", &struct {
+			Has bool
+			A   *uint8 `struc:"uint8,optional=Has"`
+			B   *uint8 `struc:"uint8,optional=Has"`
+		}{}, "already marks the presence"},
+		{"flag after", &struct {
+			V   *uint8 `struc:"uint8,optional=Has"`
+			Has bool
+		}{}, "must precede"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sizeof(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	type inner struct {
+		A uint16
+		B []byte `struc:"[4]byte"`
+	}
+	for _, v := range []interface{}{
+		&struct {
+			V *uint8 `struc:"uint8"`
+		}{},
+		// 嵌套结构体指针在计算大小时不能解引用 nil
+		&struct {
+			P *inner
+		}{},
+		&struct {
+			N uint8
+			P *inner
+		}{},
+	} {
+		var buf bytes.Buffer
+		err := Pack(&buf, v)
+		if err == nil || !strings.Contains(err.Error(), "nil pointer") {
+			t.Fatalf("expected nil pointer error for %T, got %v", v, err)
+		}
+	}
+}
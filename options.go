@@ -21,13 +21,49 @@ type Options struct {
 	PtrSize int
 
 	// Order 指定字节序（大端或小端）
-	// 如果为 nil，则使用大端序
+	// 如果为 nil，则使用字段和结构体声明的字节序，都未声明时使用大端序
 	Order binary.ByteOrder
+
+	// OrderMode 指定 Order 与字段、结构体声明的字节序之间的优先级
+	// 默认为 OrderForce，即 Order 覆盖所有字段
+	OrderMode OrderMode
+
+	// scope 打包和解包嵌套结构体时外层结构体值的链
+	scope *structScope
+
+	// Layout 指定按 C 编译器规则计算字段偏移、对齐和尾部填充
+	// 如果为 nil，则字段之间没有填充；PtrSize 未设置时取布局的值，
+	// 布局的字节序只作用于未声明字节序的字段（与 OrderDefault 相同）
+	Layout *Layout
 }
 
+// OrderMode 决定 Options.Order 的作用范围
+type OrderMode int
+
+const (
+	// OrderForce Options.Order 覆盖所有字段的字节序，包括标签和结构体声明的字节序
+	OrderForce OrderMode = iota
+
+	// OrderDefault Options.Order 只作用于标签和结构体都未声明字节序的字段
+	OrderDefault
+)
+
 // Validate 验证选项的有效性
 // 检查指针大小是否合法，并设置默认值
 func (o *Options) Validate() error {
+	if o.Layout != nil {
+		if err := o.Layout.validate(); err != nil {
+			return err
+		}
+		if o.PtrSize == 0 {
+			o.PtrSize = o.Layout.PtrSize
+		} else if o.PtrSize != o.Layout.PtrSize {
+			return fmt.Errorf("Options.PtrSize %d conflicts with %d-bit layout %s", o.PtrSize, o.Layout.PtrSize, o.Layout)
+		}
+	}
+	if o.OrderMode != OrderForce && o.OrderMode != OrderDefault {
+		return fmt.Errorf("invalid Options.OrderMode: %d", o.OrderMode)
+	}
 	if o.PtrSize == 0 {
 		o.PtrSize = 32 // 设置默认指针大小
 	} else {
@@ -41,6 +77,15 @@ func (o *Options) Validate() error {
 	return nil
 }
 
+// defaultOrder 返回未声明字节序的值使用的字节序
+// 依次取 Options.Order 和布局的字节序，都未设置时返回 nil
+func (o *Options) defaultOrder() binary.ByteOrder {
+	if o.Order == nil && o.Layout != nil {
+		return o.Layout.Order
+	}
+	return o.Order
+}
+
 func init() {
 	_ = defaultPackingOptions.Validate()
 }
@@ -35,6 +35,13 @@ func (b *OptionsBuilder) WithByteOrder(order binary.ByteOrder) *OptionsBuilder {
 	return b
 }
 
+// WithDefaultByteOrder 设置默认字节序，只作用于未声明字节序的字段
+func (b *OptionsBuilder) WithDefaultByteOrder(order binary.ByteOrder) *OptionsBuilder {
+	b.options.Order = order
+	b.options.OrderMode = OrderDefault
+	return b
+}
+
 // WithLittleEndian 设置为小端字节序
 func (b *OptionsBuilder) WithLittleEndian() *OptionsBuilder {
 	b.options.Order = binary.LittleEndian
@@ -47,6 +54,12 @@ func (b *OptionsBuilder) WithBigEndian() *OptionsBuilder {
 	return b
 }
 
+// WithLayout 设置 C 结构体布局
+func (b *OptionsBuilder) WithLayout(layout *Layout) *OptionsBuilder {
+	b.options.Layout = layout
+	return b
+}
+
 // Build 构建最终的 Options 对象并验证
 func (b *OptionsBuilder) Build() (*Options, error) {
 	if err := b.options.Validate(); err != nil {
@@ -95,6 +108,12 @@ func PtrSizeOptions(size int) *Options {
 	return builder.WithPtrSize(size).MustBuild()
 }
 
+// LayoutOptions 返回按指定 C 结构体布局打包的配置选项
+func LayoutOptions(layout *Layout) *Options {
+	builder := NewOptionsBuilder()
+	return builder.WithLayout(layout).MustBuild()
+}
+
 // CustomOptions 返回自定义配置选项
 func CustomOptions(align, ptrSize int, order binary.ByteOrder) *Options {
 	builder := NewOptionsBuilder()
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -19,15 +20,78 @@ import (
 // - sizeof=Field: 指定字段大小来源
 // - skip: 跳过该字段
 // - sizefrom=Field: 指定长度来源字段
+// - sizeof=/sizefrom= 路径: Header.Length 引用嵌套结构体的字段，../Count 引用外层结构体的字段
+// - sizefrom=IHL*4-20: 长度由之前字段的整数表达式计算
+// - sizeof=Field,expr=(x+20)/4: 打包时长度字段写入表达式的值（x 为实际长度），与 sizefrom 表达式互逆
+// - bytesof=Field / sizefrom=Field,bytes: 长度字段保存目标字段打包后的字节数，而非元素个数
+// - bits=N: 位域宽度，连续的位域字段共享同一个存储单元
+// - bitorder=msb/lsb: 位域在存储单元中的排列顺序（默认 msb，即第一个字段占用最高位）
+// - prefix=uint16: 在字段内容之前内联写入长度前缀（元素个数）
+// - pstring8/pstring16/pstring32: 带 8/16/32 位长度前缀的字符串，等价于 []byte,prefix=uintN
+// - union,switch=Field: 接口字段，按判别字段的值选择通过 RegisterUnion 注册的具体类型
+// - if=Expr: 条件字段，仅当表达式（如 Flags&0x04、Version>=2）非零时才存在
+// - optional=Field: 可选指针字段，由之前的 bool 或 bits=1 字段标记是否存在，nil 不占用空间
+// - optional,presence=uint8: 可选指针字段，在内容之前内联写入存在标记
+// - const=Value / magic=Value: 常量字段，打包时总是写入该值，解包时校验
+// - unix32/unix64/unixms64/ntp64/filetime/dosdatetime/gpsweek: time.Time 和 time.Duration 字段的时间戳编码
+// - int16,scale=0.01,offset=-40 / q16.16: float 字段按缩放或定点整数保存，round= 指定取整方式，saturate 截断越界值
+// - float16/bfloat16/fp8e4m3/fp8e5m2: float 字段按半精度、bfloat16 或 FP8 保存；Float16 等类型的字段无需标签
+// - bcd/[N]bcd/octal=N/decimal=N/hexascii=N: 整数字段按压缩 BCD 或定宽 ASCII 数字保存，justify=、pad=、term= 指定对齐、填充和结束符
+// - checksum=crc32,range=Header:Payload: 校验和字段，打包时按覆盖字段的字节计算并回填，解包时校验
+// - rest/eof: 最后一个字段读取剩余的全部输入，无需长度字段
+// - until=0xff / until=zero / until=name: 以终止元素结尾的切片，打包时自动写入终止元素
+// - align=N: 字段从所在结构体起始位置的 N 字节整数倍处开始，之前以零填充
+// - _ struct{} `struc:"size=512"`: 结构体级别选项，定长记录打包时以零填充到 N 字节
+// - _ struct{} `struc:"little"`: 结构体的默认字节序，作用于未声明字节序的字段和嵌套结构体
+// - offsetfrom=Field / offsetof=Field: 按偏移存放的字段，打包时放在固定部分之后并回填偏移，解包时需要 io.ReaderAt 数据源
 
 // strucTag 定义了结构体字段标签的解析结果
 // 包含了字段的类型、字节序、大小引用等信息
 type strucTag struct {
-	Type     string           // 字段类型（如 int32, uint8 等）
-	Order    binary.ByteOrder // 字节序（大端或小端）
-	Sizeof   string           // 大小引用字段名
-	Skip     bool             // 是否跳过该字段
-	Sizefrom string           // 长度来源字段名
+	Type         string           // 字段类型（如 int32, uint8 等）
+	Order        binary.ByteOrder // 字节序（大端或小端）
+	HasOrder     bool             // 是否显式声明了字节序
+	Sizeof       string           // 大小引用字段名
+	Skip         bool             // 是否跳过该字段
+	Sizefrom     string           // 长度来源字段名
+	Bytesof      string           // 字节长度引用字段名
+	Expr         string           // 由长度 x 计算长度字段值的表达式
+	Bytes        bool             // sizefrom 引用的长度是否为字节数
+	Bits         int              // 位域宽度，0 表示非位域
+	BitLSB       bool             // 位域是否从最低位开始排列
+	Prefix       string           // 内联长度前缀的类型
+	Switch       string           // 联合字段的判别字段名
+	If           string           // 字段存在的条件表达式
+	Optional     bool             // 是否为可选指针字段
+	OptionalFrom string           // 可选字段的存在标记字段名
+	Presence     string           // 可选字段内联存在标记的类型
+	Const        string           // 常量字段的值
+	HasConst     bool             // 是否声明了常量
+	Checksum     string           // 校验和算法名称
+	Range        string           // 校验和覆盖的字段范围（From:To）
+	Offsetfrom   string           // 保存该字段偏移的字段名
+	Offsetof     string           // 该字段保存其偏移的字段名
+	Rest         bool             // 是否读取剩余的全部输入
+	Until        string           // 终止元素（数值、zero 或已注册的名称）
+	Align        int              // 字段起始偏移的对齐字节数
+	Size         int              // 定长记录的字节数（仅用于 _ 标记字段）
+	Scale        float64          // 缩放字段线上值每单位对应的字段值
+	Offset       float64          // 缩放字段线上值为 0 时的字段值
+	HasScale     bool             // 是否声明了 scale=、offset= 或 q 格式
+	Round        string           // 缩放字段的取整方式
+	Saturate     bool             // 缩放字段超出范围时是否截断到边界
+	Width        int              // ASCII 数字字段的字节宽度
+	Justify      string           // BCD 和 ASCII 数字字段的对齐方式
+	Pad          string           // ASCII 数字字段的填充字符
+	Term         string           // ASCII 数字字段的结束符
+	err          error            // 标签解析过程中遇到的第一个错误
+}
+
+// setError 记录标签解析中的第一个错误
+func (t *strucTag) setError(format string, args ...interface{}) {
+	if t.err == nil {
+		t.err = fmt.Errorf(format, args...)
+	}
 }
 
 // parseStrucTag 解析结构体字段的标签
@@ -36,6 +100,7 @@ func parseStrucTag(fieldTag reflect.StructTag) *strucTag {
 	// 初始化标签结构体，默认使用大端字节序
 	parsedTag := &strucTag{
 		Order: binary.BigEndian,
+		Scale: 1,
 	}
 
 	// 获取 struc 标签，如果不存在则尝试获取 struct 标签
@@ -51,19 +116,140 @@ func parseStrucTag(fieldTag reflect.StructTag) *strucTag {
 	}
 
 	for _, option := range strings.Split(tagString, ",") {
-		if strings.HasPrefix(option, "sizeof=") {
-			parts := strings.SplitN(option, "=", 2)
-			parsedTag.Sizeof = parts[1]
-		} else if strings.HasPrefix(option, "sizefrom=") {
-			parts := strings.SplitN(option, "=", 2)
-			parsedTag.Sizefrom = parts[1]
-		} else if option == "big" {
-			parsedTag.Order = binary.BigEndian
-		} else if option == "little" {
-			parsedTag.Order = binary.LittleEndian
-		} else if option == "skip" {
-			parsedTag.Skip = true
-		} else if option != "" {
+		key, value, hasValue := strings.Cut(option, "=")
+		if !hasValue {
+			switch option {
+			case "big":
+				parsedTag.Order = binary.BigEndian
+				parsedTag.HasOrder = true
+			case "little":
+				parsedTag.Order = binary.LittleEndian
+				parsedTag.HasOrder = true
+			case "skip":
+				parsedTag.Skip = true
+			case "optional":
+				parsedTag.Optional = true
+			case "rest", "eof":
+				parsedTag.Rest = true
+			case "bytes":
+				parsedTag.Bytes = true
+			case "saturate":
+				parsedTag.Saturate = true
+			case "":
+			default:
+				if prefixType, ok := prefixedStringTypes[option]; ok {
+					parsedTag.Type = "[]byte"
+					parsedTag.Prefix = prefixType
+					continue
+				}
+				if integerType, fractionBits, ok := parseQFormat(option); ok {
+					if parsedTag.Scale != 1 {
+						parsedTag.setError("struc: q format `%s` conflicts with `scale=`", option)
+					}
+					parsedTag.Type = integerType
+					parsedTag.Scale = math.Ldexp(1, -fractionBits)
+					parsedTag.HasScale = true
+					continue
+				}
+				parsedTag.Type = option
+			}
+			continue
+		}
+
+		switch key {
+		case "sizeof":
+			parsedTag.Sizeof = value
+		case "sizefrom":
+			parsedTag.Sizefrom = value
+		case "bytesof":
+			parsedTag.Bytesof = value
+		case "expr":
+			parsedTag.Expr = value
+		case "bits":
+			bits, err := strconv.Atoi(value)
+			if err != nil || bits <= 0 {
+				parsedTag.setError("struc: invalid bit width `bits=%s`", value)
+				continue
+			}
+			parsedTag.Bits = bits
+		case "prefix":
+			parsedTag.Prefix = value
+		case "switch":
+			parsedTag.Switch = value
+		case "if":
+			parsedTag.If = value
+		case "optional":
+			parsedTag.Optional = true
+			parsedTag.OptionalFrom = value
+		case "presence":
+			parsedTag.Presence = value
+		case "const", "magic":
+			parsedTag.Const = value
+			parsedTag.HasConst = true
+		case "checksum":
+			parsedTag.Checksum = value
+		case "range":
+			parsedTag.Range = value
+		case "offsetfrom":
+			parsedTag.Offsetfrom = value
+		case "offsetof":
+			parsedTag.Offsetof = value
+		case "until":
+			parsedTag.Until = value
+		case "align":
+			align, err := strconv.Atoi(value)
+			if err != nil || align <= 0 {
+				parsedTag.setError("struc: invalid alignment `align=%s`", value)
+				continue
+			}
+			parsedTag.Align = align
+		case "size":
+			size, err := strconv.Atoi(value)
+			if err != nil || size <= 0 {
+				parsedTag.setError("struc: invalid record size `size=%s`", value)
+				continue
+			}
+			parsedTag.Size = size
+		case "scale", "offset":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				parsedTag.setError("struc: invalid `%s=%s`", key, value)
+				continue
+			}
+			if key == "offset" {
+				parsedTag.Offset = number
+			} else if parsedTag.HasScale && parsedTag.Scale != 1 {
+				parsedTag.setError("struc: `scale=%s` conflicts with a q format", value)
+			} else {
+				parsedTag.Scale = number
+			}
+			parsedTag.HasScale = true
+		case "round":
+			parsedTag.Round = value
+		case "octal", "decimal", "hexascii":
+			width, err := strconv.Atoi(value)
+			if err != nil || width <= 0 {
+				parsedTag.setError("struc: invalid width `%s=%s`", key, value)
+				continue
+			}
+			parsedTag.Type = key
+			parsedTag.Width = width
+		case "justify":
+			parsedTag.Justify = value
+		case "pad":
+			parsedTag.Pad = value
+		case "term":
+			parsedTag.Term = value
+		case "bitorder":
+			switch value {
+			case "msb":
+				parsedTag.BitLSB = false
+			case "lsb":
+				parsedTag.BitLSB = true
+			default:
+				parsedTag.setError("struc: invalid bit order `bitorder=%s` (must be msb or lsb)", value)
+			}
+		default:
 			parsedTag.Type = option
 		}
 	}
@@ -78,6 +264,9 @@ var customBinaryerType = reflect.TypeOf((*CustomBinaryer)(nil)).Elem()
 // parseStructField 解析单个结构体字段
 func parseStructField(structField reflect.StructField) (fieldDesc *Field, fieldTag *strucTag, err error) {
 	fieldTag = parseStrucTag(structField.Tag)
+	if fieldTag.err != nil {
+		return nil, fieldTag, fmt.Errorf("%w (field `%s`)", fieldTag.err, structField.Name)
+	}
 	var ok bool
 
 	fieldDesc = acquireField()
@@ -109,7 +298,8 @@ func parseStructField(structField reflect.StructField) (fieldDesc *Field, fieldT
 	if !implementsCustom && fieldType.Kind() != reflect.Ptr {
 		implementsCustom = reflect.PointerTo(fieldType).Implements(customBinaryerType)
 	}
-	if implementsCustom {
+	// Float16 等小浮点类型按对应的线上类型处理，以便支持字段字节序和切片
+	if _, isMinifloat := defaultMinifloatType(fieldType); implementsCustom && !isMinifloat {
 		fieldDesc.Type = CustomType
 		return
 	}
@@ -142,7 +332,11 @@ func parseStructField(structField reflect.StructField) (fieldDesc *Field, fieldT
 	case reflect.TypeOf(Off_t(0)):
 		fieldDesc.Type = OffType
 	default:
-		if defTypeOk {
+		if int128Type, isInt128 := defaultInt128Type(structField.Type); isInt128 {
+			fieldDesc.Type = int128Type
+		} else if minifloatType, isMinifloat := defaultMinifloatType(structField.Type); isMinifloat {
+			fieldDesc.Type = minifloatType
+		} else if defTypeOk {
 			fieldDesc.Type = fieldDesc.defType
 		} else {
 			releaseField(fieldDesc)
@@ -154,35 +348,70 @@ func parseStructField(structField reflect.StructField) (fieldDesc *Field, fieldT
 }
 
 // handleSizeofTag 处理字段的 sizeof 标签
-func handleSizeofTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField, sizeofMap map[string][]int) error {
+func handleSizeofTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField, sizeofMap map[string][]int, scope *parseScope) error {
 	if fieldTag.Sizeof != "" {
-		targetField, ok := structType.FieldByName(fieldTag.Sizeof)
-		if !ok {
+		targetField, index, up, err := resolveFieldPath(structType, fieldTag.Sizeof, scope)
+		if err != nil {
+			if isFieldPath(fieldTag.Sizeof) {
+				return fmt.Errorf("%w (field `%s`)", err, field.Name)
+			}
 			return fmt.Errorf("struc: `sizeof=%s` field does not exist", fieldTag.Sizeof)
 		}
-		fieldDesc.Sizeof = targetField.Index
-		sizeofMap[fieldTag.Sizeof] = field.Index
+		targetType := targetField.Type
+		if targetType.Kind() == reflect.Ptr {
+			targetType = targetType.Elem()
+		}
+		if targetType.Kind() == reflect.Struct {
+			return fmt.Errorf("struc: `sizeof=%s` refers to a struct with no element count; use `bytesof=%s` for its byte length", fieldTag.Sizeof, fieldTag.Sizeof)
+		}
+		fieldDesc.Sizeof = index
+		fieldDesc.sizeofUp = up
+		if up == 0 {
+			sizeofMap[fieldTag.Sizeof] = field.Index
+		} else {
+			exportSizeof(scope, up, targetField, field)
+		}
 	}
 	return nil
 }
 
 // handleSizefromTag 处理字段的 sizefrom 标签
-func handleSizefromTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField, sizeofMap map[string][]int) error {
+func handleSizefromTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField, sizeofMap map[string][]int, scope *parseScope) error {
 	if sizefrom, ok := sizeofMap[field.Name]; ok {
 		fieldDesc.Sizefrom = sizefrom
+	} else if sizefrom, ok := importSizefrom(scope, field); ok {
+		fieldDesc.Sizefrom = sizefrom
+		fieldDesc.sizefromUp = 1
 	}
 	if fieldTag.Sizefrom != "" {
-		sourceField, ok := structType.FieldByName(fieldTag.Sizefrom)
-		if !ok {
+		if !isFieldRef(fieldTag.Sizefrom) {
+			return handleLengthExpr(fieldDesc, fieldTag, structType, field)
+		}
+		sourceField, index, up, err := resolveFieldPath(structType, fieldTag.Sizefrom, scope)
+		if err != nil {
+			if isFieldPath(fieldTag.Sizefrom) {
+				return fmt.Errorf("%w (field `%s`)", err, field.Name)
+			}
 			return fmt.Errorf("struc: `sizefrom=%s` field does not exist", fieldTag.Sizefrom)
 		}
-		fieldDesc.Sizefrom = sourceField.Index
+		if up > 0 || len(index) > 1 {
+			switch sourceField.Type.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			default:
+				return fmt.Errorf("struc: `sizefrom=%s` must refer to an integer field (field `%s`)", fieldTag.Sizefrom, field.Name)
+			}
+		}
+		fieldDesc.Sizefrom = index
+		fieldDesc.sizefromUp = up
 	}
 	return nil
 }
 
 // handleNestedStruct 处理嵌套结构体字段
-func handleNestedStruct(fieldDesc *Field, field reflect.StructField) error {
+// 字段声明的字节序（标签或所在结构体的默认字节序）作为嵌套结构体的默认字节序向内传递；
+// 匿名嵌入或引用外层字段的嵌套结构体在外层结构体的上下文中解析
+func handleNestedStruct(fieldDesc *Field, field reflect.StructField, structType reflect.Type, sizeofMap map[string][]int, scope *parseScope) error {
 	if fieldDesc.Type == Struct {
 		fieldType := field.Type
 		if fieldDesc.IsPointer {
@@ -192,25 +421,44 @@ func handleNestedStruct(fieldDesc *Field, field reflect.StructField) error {
 			fieldType = fieldType.Elem()
 		}
 		tempValue := reflect.New(fieldType)
-		nestedFields, err := parseFields(tempValue.Elem())
+		var nestedOrder binary.ByteOrder
+		if fieldDesc.orderDeclared {
+			nestedOrder = fieldDesc.ByteOrder
+		}
+		var nestedScope *parseScope
+		if needsParseScope(field, fieldType) {
+			nestedScope = &parseScope{
+				structType: structType,
+				name:       field.Name,
+				embedded:   field.Anonymous && !fieldDesc.IsPointer && !fieldDesc.IsSlice,
+				sizeofMap:  sizeofMap,
+				up:         scope,
+			}
+			if !fieldDesc.IsPointer && !fieldDesc.IsSlice {
+				nestedScope.index = field.Index
+			}
+		}
+		nestedFields, err := parseNestedFields(tempValue.Elem(), nestedOrder, nestedScope)
 		if err != nil {
 			return err
 		}
 		fieldDesc.NestFields = nestedFields
+		fieldDesc.scoped = nestedFields.usesScope()
 	}
 	return nil
 }
 
 // validateSliceLength 验证切片长度
 func validateSliceLength(fieldDesc *Field, field reflect.StructField) error {
-	if fieldDesc.Length == -1 && fieldDesc.Sizefrom == nil {
+	if fieldDesc.Length == -1 && fieldDesc.Sizefrom == nil && fieldDesc.Prefix == Invalid && !fieldDesc.Rest && fieldDesc.until == nil {
 		return fmt.Errorf("struc: field `%s` is a slice with no length or sizeof field", field.Name)
 	}
 	return nil
 }
 
 // parseFieldsLocked 在加锁状态下解析结构体的所有字段
-func parseFieldsLocked(structValue reflect.Value) (Fields, error) {
+// inheritedOrder 为外层结构体传递的默认字节序，scope 为外层结构体的信息，顶层结构体均为 nil
+func parseFieldsLocked(structValue reflect.Value, inheritedOrder binary.ByteOrder, scope *parseScope) (Fields, error) {
 	for structValue.Kind() == reflect.Ptr {
 		structValue = structValue.Elem()
 	}
@@ -220,6 +468,14 @@ func parseFieldsLocked(structValue reflect.Value) (Fields, error) {
 		return nil, errors.New("struc: Struct has no fields.")
 	}
 
+	defaultOrder, err := structByteOrder(structType)
+	if err != nil {
+		return nil, err
+	}
+	if defaultOrder == nil {
+		defaultOrder = inheritedOrder
+	}
+
 	sizeofMap := acquireSizeofMap()
 	defer releaseSizeofMap(sizeofMap)
 
@@ -228,6 +484,20 @@ func parseFieldsLocked(structValue reflect.Value) (Fields, error) {
 	for i := 0; i < structType.NumField(); i++ {
 		field := structType.Field(i)
 
+		// `_` 字段不参与打包，但可以携带结构体级别的选项
+		if field.Name == "_" {
+			marker, err := parseStructMarker(field)
+			if err != nil {
+				releaseFields(fields)
+				return nil, err
+			}
+			if marker != nil {
+				marker.Index = i
+				fields[i] = marker
+			}
+			continue
+		}
+
 		fieldDesc, fieldTag, err := parseStructField(field)
 		if err != nil {
 			releaseFields(fields)
@@ -240,14 +510,129 @@ func parseFieldsLocked(structValue reflect.Value) (Fields, error) {
 		}
 
 		fieldDesc.Index = i
+		applyStructByteOrder(fieldDesc, fieldTag, defaultOrder)
+
+		if err := handleSizeofTag(fieldDesc, fieldTag, structType, field, sizeofMap, scope); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleSizefromTag(fieldDesc, fieldTag, structType, field, sizeofMap, scope); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleBytesofTag(fieldDesc, fieldTag, structType, field, fields, sizeofMap); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleInverseExprTag(fieldDesc, fieldTag, structType, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleUnionTag(fieldDesc, fieldTag, structType, field, fields); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleCStringTag(fieldDesc, fieldTag, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
 
-		if err := handleSizeofTag(fieldDesc, fieldTag, structType, field, sizeofMap); err != nil {
+		if err := handleInt128Tag(fieldDesc, field); err != nil {
 			releaseField(fieldDesc)
 			releaseFields(fields)
 			return nil, err
 		}
 
-		if err := handleSizefromTag(fieldDesc, fieldTag, structType, field, sizeofMap); err != nil {
+		if err := handleTimeTag(fieldDesc, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleScaleTag(fieldDesc, fieldTag, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleMinifloatTag(fieldDesc, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleNumericTag(fieldDesc, fieldTag, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleBitfieldTag(fieldDesc, fieldTag, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleIfTag(fieldDesc, fieldTag, structType, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleOptionalTag(fieldDesc, fieldTag, structType, field, fields); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handlePrefixTag(fieldDesc, fieldTag, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleConstTag(fieldDesc, fieldTag, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleOffsetTag(fieldDesc, fieldTag, structType, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleRestTag(fieldDesc, fieldTag, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleUntilTag(fieldDesc, fieldTag, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleChecksumTag(fieldDesc, fieldTag, structType, field); err != nil {
+			releaseField(fieldDesc)
+			releaseFields(fields)
+			return nil, err
+		}
+
+		if err := handleAlignTag(fieldDesc, fieldTag, field); err != nil {
 			releaseField(fieldDesc)
 			releaseFields(fields)
 			return nil, err
@@ -259,7 +644,7 @@ func parseFieldsLocked(structValue reflect.Value) (Fields, error) {
 			return nil, err
 		}
 
-		if err := handleNestedStruct(fieldDesc, field); err != nil {
+		if err := handleNestedStruct(fieldDesc, field, structType, sizeofMap, scope); err != nil {
 			releaseField(fieldDesc)
 			releaseFields(fields)
 			return nil, err
@@ -267,6 +652,20 @@ func parseFieldsLocked(structValue reflect.Value) (Fields, error) {
 
 		fields[i] = fieldDesc
 	}
+
+	layoutBitfields(fields)
+	if err := linkOffsetFields(fields); err != nil {
+		releaseFields(fields)
+		return nil, err
+	}
+	if err := checkRestField(fields); err != nil {
+		releaseFields(fields)
+		return nil, err
+	}
+	if err := linkLengthExprs(fields, structType); err != nil {
+		releaseFields(fields)
+		return nil, err
+	}
 	return fields, nil
 }
 
@@ -311,7 +710,7 @@ func parseFields(structValue reflect.Value) (Fields, error) {
 		return cached, nil
 	}
 
-	fields, err := parseFieldsLocked(structValue)
+	fields, err := parseFieldsLocked(structValue, nil, nil)
 	if err != nil {
 		return nil, err
 	}
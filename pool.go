@@ -245,8 +245,39 @@ func releaseField(f *Field) {
 	f.ByteOrder = binary.BigEndian
 	f.Sizeof = nil
 	f.Sizefrom = nil
+	f.Bytesof = nil
 	f.NestFields = nil
+	f.BitSize = 0
+	f.Prefix = Invalid
+	f.Switch = nil
+	f.Optional = nil
+	f.Presence = Invalid
 	f.kind = reflect.Invalid
+	f.bitOffset = 0
+	f.bitUnit = Invalid
+	f.bitLSB = false
+	f.bitFirst = false
+	f.bitLast = false
+	f.switchFor = nil
+	f.condition = nil
+	f.optionalFor = nil
+	f.constValue = reflect.Value{}
+	f.checksum = nil
+	f.Offsetfrom = nil
+	f.Rest = false
+	f.offsetFor = nil
+	f.until = nil
+	f.byteLength = false
+	f.lengthExpr = nil
+	f.inverseExpr = nil
+	f.Align = 0
+	f.recordSize = 0
+	f.orderDeclared = false
+	f.sizeofUp = 0
+	f.sizefromUp = 0
+	f.scoped = false
+	f.scale = nil
+	f.numeric = nil
 
 	fieldPool.Put(f)
 }
@@ -0,0 +1,137 @@
+package struc
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// 内联长度前缀支持
+//
+// 标签示例：
+//
+//	type Record struct {
+//		Name  string   `struc:"pstring8"`
+//		Data  []byte   `struc:"[]byte,prefix=uint16,little"`
+//		Items []Item   `struc:"prefix=uvarint"`
+//	}
+//
+// 长度前缀由字段自身写入和读取，记录的是元素个数（字符串和字节切片即字节数），
+// 使用字段的字节序，不需要在 Go 结构体中额外声明长度字段。
+
+// prefixedStringTypes 定义了带长度前缀的字符串类型到前缀类型的映射
+var prefixedStringTypes = map[string]string{
+	"pstring8":  "uint8",
+	"pstring16": "uint16",
+	"pstring32": "uint32",
+}
+
+// handlePrefixTag 处理字段的 prefix 标签
+func handlePrefixTag(fieldDesc *Field, fieldTag *strucTag, field reflect.StructField) error {
+	if fieldTag.Prefix == "" {
+		return nil
+	}
+
+	prefixType, ok := typeStrToType[fieldTag.Prefix]
+	if !ok {
+		return fmt.Errorf("struc: unknown prefix type `prefix=%s` on field `%s`", fieldTag.Prefix, field.Name)
+	}
+	switch prefixType {
+	case Int8, Int16, Int32, Int64, Uint8, Uint16, Uint32, Uint64, Uvarint, Varint, Sleb128:
+	default:
+		return fmt.Errorf("struc: prefix type `%s` on field `%s` must be an integer type", fieldTag.Prefix, field.Name)
+	}
+
+	if fieldDesc.IsPointer || fieldDesc.IsArray {
+		return fmt.Errorf("struc: prefixed field `%s` must be a slice or string", field.Name)
+	}
+	if !fieldDesc.IsSlice && fieldDesc.kind != reflect.String {
+		return fmt.Errorf("struc: prefixed field `%s` must be a slice or string", field.Name)
+	}
+	if fieldDesc.IsSlice && fieldDesc.Length != -1 {
+		return fmt.Errorf("struc: prefixed field `%s` must not have a fixed length", field.Name)
+	}
+	if fieldDesc.Sizefrom != nil || fieldDesc.Sizeof != nil {
+		return fmt.Errorf("struc: prefixed field `%s` does not support sizeof/sizefrom", field.Name)
+	}
+
+	fieldDesc.Prefix = prefixType
+	return nil
+}
+
+// prefixSize 返回长度前缀占用的字节数
+func (f *Field) prefixSize(length int) int {
+	if f.Prefix.IsVarint() {
+		return varintSize(f.Prefix, uint64(length))
+	}
+	return f.Prefix.Size()
+}
+
+// maxPrefixLength 返回长度前缀能够表示的最大长度
+func (f *Field) maxPrefixLength() uint64 {
+	switch f.Prefix {
+	case Int8:
+		return 1<<7 - 1
+	case Uint8:
+		return 1<<8 - 1
+	case Int16:
+		return 1<<15 - 1
+	case Uint16:
+		return 1<<16 - 1
+	case Int32:
+		return 1<<31 - 1
+	case Uint32:
+		return 1<<32 - 1
+	default:
+		return 1<<63 - 1
+	}
+}
+
+// packPrefixed 写入长度前缀，并紧接着写入字段内容
+func (f *Field) packPrefixed(buffer []byte, fieldValue reflect.Value, options *Options) (int, error) {
+	length := fieldValue.Len()
+	if uint64(length) > f.maxPrefixLength() {
+		return 0, fmt.Errorf("struc: field %s length %d overflows %v prefix", f.Name, length, f.Prefix)
+	}
+
+	var position int
+	if f.Prefix.IsVarint() {
+		position = putVarint(buffer, f.Prefix, uint64(length))
+	} else {
+		if err := f.writeInteger(buffer, uint64(length), f.Prefix, f.determineByteOrder(options)); err != nil {
+			return 0, err
+		}
+		position = f.Prefix.Size()
+	}
+
+	var bytesWritten int
+	var err error
+	if f.IsSlice {
+		bytesWritten, err = f.packSliceValue(buffer[position:], fieldValue, length, options)
+	} else {
+		bytesWritten, err = f.packSingleValue(buffer[position:], fieldValue, length, options)
+	}
+	return position + bytesWritten, err
+}
+
+// unpackPrefix 从读取器中读取长度前缀
+func (f *Field) unpackPrefix(reader io.Reader, options *Options, scratch *scratchArena) (int, error) {
+	var length uint64
+	if f.Prefix.IsVarint() {
+		var err error
+		if length, err = readVarint(reader, f.Prefix, scratch); err != nil {
+			return 0, err
+		}
+	} else {
+		buffer := scratch.Get(f.Prefix.Size())
+		if _, err := io.ReadFull(reader, buffer); err != nil {
+			return 0, err
+		}
+		length = f.readInteger(buffer, f.Prefix, f.determineByteOrder(options))
+	}
+
+	if length > f.maxPrefixLength() {
+		return 0, fmt.Errorf("struc: field %s has invalid length prefix %d", f.Name, int64(length))
+	}
+	return int(length), nil
+}
@@ -0,0 +1,101 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type prefixItem struct {
+	ID   uint16
+	Name string `struc:"pstring8"`
+}
+
+type prefixExample struct {
+	Name   string       `struc:"pstring8"`
+	Data   []byte       `struc:"[]byte,prefix=uint16,little"`
+	Values []int32      `struc:"[]int32,prefix=uint32"`
+	Items  []prefixItem `struc:"prefix=uvarint"`
+	Text   string       `struc:"prefix=uint16"`
+}
+
+func TestPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	in := &prefixExample{
+		Name:   "abc",
+		Data:   []byte{1, 2},
+		Values: []int32{-1, 2},
+		Items:  []prefixItem{{1, "x"}, {2, "yz"}},
+		Text:   "hi",
+	}
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		3, 'a', 'b', 'c',
+		2, 0, 1, 2,
+		0, 0, 0, 2, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 2,
+		2, 0, 1, 1, 'x', 0, 2, 2, 'y', 'z',
+		0, 2, 'h', 'i',
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("prefix pack: got %v, want %v", buf.Bytes(), want)
+	}
+	if size, err := Sizeof(in); err != nil || size != len(want) {
+		t.Fatalf("prefix sizeof: got %d (%v), want %d", size, err, len(want))
+	}
+	out := &prefixExample{}
+	if err := Unpack(&buf, out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("prefix round trip: got %+v, want %+v", out, in)
+	}
+}
+
+type prefixEmpty struct {
+	Data []byte `struc:"[]byte,prefix=uint8"`
+	Name string `struc:"pstring16"`
+	Tail uint8
+}
+
+func TestPrefixEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Pack(&buf, &prefixEmpty{Tail: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{0, 0, 0, 5}) {
+		t.Fatalf("prefix pack: got %v", buf.Bytes())
+	}
+	out := &prefixEmpty{}
+	if err := Unpack(&buf, out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Data) != 0 || out.Name != "" || out.Tail != 5 {
+		t.Fatalf("prefix unpack: got %+v", out)
+	}
+}
+
+func TestPrefixOverflow(t *testing.T) {
+	var buf bytes.Buffer
+	in := &prefixEmpty{Data: make([]byte, 256)}
+	if err := Pack(&buf, in); err == nil {
+		t.Fatal("failed to error on prefix overflow")
+	}
+}
+
+type prefixBadType struct {
+	Data []byte `struc:"prefix=float32"`
+}
+
+type prefixFixed struct {
+	Data []byte `struc:"[4]byte,prefix=uint8"`
+}
+
+func TestPrefixParseErrors(t *testing.T) {
+	for _, v := range []interface{}{&prefixBadType{}, &prefixFixed{}} {
+		if err := parseTest(v); err == nil {
+			t.Errorf("failed to error on invalid prefix %T", v)
+		}
+	}
+}
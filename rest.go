@@ -0,0 +1,110 @@
+package struc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// 剩余输入字段
+//
+// 结构体的最后一个字段可以使用 rest（或 eof）标签，解包时读取 reader 中剩余的全部字节，
+// 不需要 sizeof/sizefrom 或长度前缀。reader 受外层窗口限制时（如带 sizefrom 的联合字段、
+// offsetfrom 字段），只读取窗口内的剩余字节。
+//
+// 支持 string、[]byte 以及定长元素的切片；结构体切片按元素依次解包直到输入结束。
+// 打包时按切片的实际长度写入。
+
+// handleRestTag 处理 rest/eof 标签
+func handleRestTag(fieldDesc *Field, fieldTag *strucTag, field reflect.StructField) error {
+	if !fieldTag.Rest {
+		return nil
+	}
+	if !(fieldDesc.IsSlice && !fieldDesc.IsArray) && field.Type.Kind() != reflect.String {
+		return fmt.Errorf("struc: rest field `%s` must be a slice or string", field.Name)
+	}
+	if fieldDesc.Sizefrom != nil || fieldDesc.Sizeof != nil || fieldDesc.Prefix != Invalid ||
+		fieldDesc.IsPointer || fieldDesc.isOptional() || fieldDesc.Offsetfrom != nil || fieldDesc.constValue.IsValid() {
+		return fmt.Errorf("struc: rest field `%s` cannot have an explicit length, offset or presence", field.Name)
+	}
+	switch fieldDesc.Type {
+	case CustomType, CString, Union, Pad, Uvarint, Varint, Sleb128:
+		return fmt.Errorf("struc: rest field `%s` must have fixed-size elements, got %s", field.Name, fieldDesc.Type)
+	}
+
+	fieldDesc.Rest = true
+	return nil
+}
+
+// checkRestField 校验 rest 字段是否为结构体中最后一个按顺序存放的字段
+func checkRestField(fields Fields) error {
+	var rest *Field
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+		if rest != nil {
+			return fmt.Errorf("struc: rest field `%s` must be the last field", rest.Name)
+		}
+		if field.Rest {
+			rest = field
+		}
+	}
+	if rest != nil && fields.hasOffsets() {
+		return fmt.Errorf("struc: rest field `%s` cannot be combined with offset fields", rest.Name)
+	}
+	return nil
+}
+
+// unpackRest 读取 reader 中剩余的全部字节并解包到 rest 字段
+func (f Fields) unpackRest(reader io.Reader, fieldValue reflect.Value, field *Field, options *Options, scratch *scratchArena) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	if field.Type == Struct {
+		return f.unpackRestStructs(data, fieldValue, field, options, scratch)
+	}
+
+	elementSize := resolveTypeForOptions(field.Type, options).Size()
+	if len(data)%elementSize != 0 {
+		return fmt.Errorf("struc: %d remaining bytes do not divide into %d-byte elements of field `%s`",
+			len(data), elementSize, field.Name)
+	}
+	// data 由 ReadAll 新分配，字符串和 []byte 字段可以直接引用
+	return field.Unpack(data, fieldValue, len(data)/elementSize, options)
+}
+
+// unpackRestStructs 依次解包结构体元素，直到剩余字节耗尽
+func (f Fields) unpackRestStructs(data []byte, fieldValue reflect.Value, field *Field, options *Options, scratch *scratchArena) error {
+	reader := bytes.NewReader(data)
+	sliceValue := reflect.MakeSlice(fieldValue.Type(), 0, 0)
+
+	for reader.Len() > 0 {
+		remaining := reader.Len()
+		elementValue := reflect.New(fieldValue.Type().Elem()).Elem()
+
+		nested := field.NestFields
+		if nested == nil {
+			var err error
+			if nested, err = parseFields(elementValue); err != nil {
+				return err
+			}
+		}
+		if err := nested.unpackWithScratch(reader, elementValue, options, scratch); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return fmt.Errorf("struc: %d trailing bytes do not form a whole element of field `%s`", remaining, field.Name)
+			}
+			return err
+		}
+		if reader.Len() == remaining {
+			return fmt.Errorf("struc: elements of rest field `%s` consume no input", field.Name)
+		}
+		sliceValue = reflect.Append(sliceValue, elementValue)
+	}
+
+	fieldValue.Set(sliceValue)
+	return nil
+}
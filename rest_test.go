@@ -0,0 +1,133 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type restPacket struct {
+	Type    uint8
+	Payload []byte `struc:"[]byte,eof"`
+}
+
+type restRecord struct {
+	ID    uint16
+	Value int32
+}
+
+type restTable struct {
+	Count   uint8
+	Records []restRecord `struc:"rest"`
+}
+
+func TestRestFields(t *testing.T) {
+	tests := []struct {
+		name string
+		in   interface{}
+		out  interface{}
+		want []byte
+	}{
+		{
+			name: "bytes",
+			in:   &restPacket{Type: 2, Payload: []byte("payload")},
+			out:  &restPacket{},
+			want: []byte{2, 'p', 'a', 'y', 'l', 'o', 'a', 'd'},
+		},
+		{
+			name: "empty",
+			in:   &restPacket{Type: 2},
+			out:  &restPacket{},
+			want: []byte{2},
+		},
+		{
+			name: "string",
+			in: &struct {
+				A    uint8
+				Text string `struc:"rest"`
+			}{A: 1, Text: "abc"},
+			out: &struct {
+				A    uint8
+				Text string `struc:"rest"`
+			}{},
+			want: []byte{1, 'a', 'b', 'c'},
+		},
+		{
+			name: "integers",
+			in: &struct {
+				Values []uint16 `struc:"[]uint16,rest"`
+			}{Values: []uint16{1, 0x0203}},
+			out: &struct {
+				Values []uint16 `struc:"[]uint16,rest"`
+			}{},
+			want: []byte{0, 1, 2, 3},
+		},
+		{
+			name: "structs",
+			in:   &restTable{Count: 2, Records: []restRecord{{1, -1}, {2, 5}}},
+			out:  &restTable{},
+			want: []byte{2, 0, 1, 0xff, 0xff, 0xff, 0xff, 0, 2, 0, 0, 0, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Pack(&buf, tt.in); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Fatalf("pack: got %x, want %x", buf.Bytes(), tt.want)
+			}
+			if err := Unpack(bytes.NewReader(tt.want), tt.out); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.in, tt.out) {
+				t.Fatalf("round trip: got %+v, want %+v", tt.out, tt.in)
+			}
+		})
+	}
+}
+
+func TestRestFieldErrors(t *testing.T) {
+	err := Unpack(bytes.NewReader([]byte{1, 2, 3}), &struct {
+		Values []uint16 `struc:"[]uint16,rest"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "do not divide") {
+		t.Fatalf("expected element size error, got %v", err)
+	}
+	err = Unpack(bytes.NewReader([]byte{1, 0, 1, 0}), &restTable{})
+	if err == nil || !strings.Contains(err.Error(), "whole element") {
+		t.Fatalf("expected partial element error, got %v", err)
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"not last", &struct {
+			Data []byte `struc:"rest"`
+			Tail uint8
+		}{}, "must be the last field"},
+		{"array", &struct {
+			Data [4]byte `struc:"rest"`
+		}{}, "must be a slice or string"},
+		{"with sizefrom", &struct {
+			N    uint8
+			Data []byte `struc:"sizefrom=N,rest"`
+		}{}, "explicit length"},
+		{"varint", &struct {
+			Data []uint64 `struc:"[]uvarint,rest"`
+		}{}, "fixed-size elements"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sizeof(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package struc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 缩放与定点数字段
+//
+// float32/float64 字段可以通过整数线上类型保存，线上值与字段值的关系为：
+//
+//	字段值 = 线上值 * scale + offset
+//
+// 例如 CAN 信号中的温度：
+//
+//	Temp float64 `struc:"int16,scale=0.01,offset=-40"`
+//
+// Q 格式定点数是 scale 为 2^-N 的简写：qM.N 为 M+N 位有符号整数（M 含符号位），
+// qN 为 N+1 位有符号整数（如 q15 为 16 位），uqM.N 为 M+N 位无符号整数。总位数必须是 8 的倍数。
+//
+// 打包时按 round= 指定的方式取整：nearest（默认，四舍五入）、even（四舍六入五成双）、
+// floor、ceil、trunc。超出线上类型范围的值默认报错，声明 saturate 时截断到范围的边界。
+
+// roundMode 是缩放字段打包时的取整方式
+type roundMode int
+
+const (
+	roundNearest roundMode = iota // 四舍五入（远离零）
+	roundEven                     // 四舍六入五成双
+	roundFloor                    // 向下取整
+	roundCeil                     // 向上取整
+	roundTrunc                    // 向零取整
+)
+
+// roundModes 定义了 round= 的取值
+var roundModes = map[string]roundMode{
+	"nearest": roundNearest,
+	"even":    roundEven,
+	"floor":   roundFloor,
+	"ceil":    roundCeil,
+	"trunc":   roundTrunc,
+}
+
+// apply 按取整方式对值取整
+func (m roundMode) apply(value float64) float64 {
+	switch m {
+	case roundEven:
+		return math.RoundToEven(value)
+	case roundFloor:
+		return math.Floor(value)
+	case roundCeil:
+		return math.Ceil(value)
+	case roundTrunc:
+		return math.Trunc(value)
+	default:
+		return math.Round(value)
+	}
+}
+
+// scaleSpec 是缩放字段的参数
+type scaleSpec struct {
+	scale    float64   // 线上值每单位对应的字段值
+	offset   float64   // 线上值为 0 时的字段值
+	round    roundMode // 打包时的取整方式
+	saturate bool      // 超出范围时是否截断到边界
+}
+
+// parseQFormat 解析 Q 格式定点类型，返回对应的整数类型名和小数位数
+func parseQFormat(option string) (string, int, bool) {
+	signed := true
+	name := option
+	if strings.HasPrefix(name, "uq") {
+		signed = false
+		name = name[2:]
+	} else if strings.HasPrefix(name, "q") {
+		name = name[1:]
+	} else {
+		return "", 0, false
+	}
+
+	integerPart, fractionPart, hasDot := strings.Cut(name, ".")
+	var integerBits, fractionBits int
+	var err error
+	if hasDot {
+		if integerBits, err = strconv.Atoi(integerPart); err != nil || integerBits < 0 {
+			return "", 0, false
+		}
+		if fractionBits, err = strconv.Atoi(fractionPart); err != nil || fractionBits < 0 {
+			return "", 0, false
+		}
+	} else {
+		if fractionBits, err = strconv.Atoi(integerPart); err != nil || fractionBits < 0 {
+			return "", 0, false
+		}
+		// qN 只有小数位，另加一个符号位
+		integerBits = 1
+		if !signed {
+			integerBits = 0
+		}
+	}
+
+	totalBits := integerBits + fractionBits
+	if totalBits < 8 || totalBits > 64 || totalBits%8 != 0 {
+		return "", 0, false
+	}
+	if signed {
+		return fmt.Sprintf("int%d", totalBits), fractionBits, true
+	}
+	return fmt.Sprintf("uint%d", totalBits), fractionBits, true
+}
+
+// isIntegerType 判断线上类型是否为整数
+func isIntegerType(t Type) bool {
+	switch t {
+	case Int8, Int16, Int24, Int32, Int40, Int48, Int56, Int64,
+		Uint8, Uint16, Uint24, Uint32, Uint40, Uint48, Uint56, Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// isSignedIntegerType 判断整数线上类型是否有符号
+func isSignedIntegerType(t Type) bool {
+	switch t {
+	case Int8, Int16, Int24, Int32, Int40, Int48, Int56, Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleScaleTag 处理 scale=、offset=、q 格式以及 round=、saturate 标签
+func handleScaleTag(fieldDesc *Field, fieldTag *strucTag, field reflect.StructField) error {
+	if !fieldTag.HasScale {
+		if fieldTag.Round != "" || fieldTag.Saturate {
+			return fmt.Errorf("struc: `round=` and `saturate` require `scale=`, `offset=` or a q format (field `%s`)", field.Name)
+		}
+		return nil
+	}
+	if !isIntegerType(fieldDesc.Type) {
+		return fmt.Errorf("struc: scaled field `%s` must use an integer wire type, got %s", field.Name, fieldDesc.Type)
+	}
+	if fieldDesc.kind != reflect.Float32 && fieldDesc.kind != reflect.Float64 {
+		return fmt.Errorf("struc: scaled field `%s` must be a float32 or float64, got %v", field.Name, field.Type)
+	}
+	if fieldDesc.BitSize > 0 || fieldDesc.Prefix != Invalid || fieldTag.Until != "" {
+		return fmt.Errorf("struc: scaled field `%s` cannot be a bitfield, prefixed or terminated", field.Name)
+	}
+	if fieldTag.Scale == 0 || math.IsInf(fieldTag.Scale, 0) || math.IsNaN(fieldTag.Scale) {
+		return fmt.Errorf("struc: invalid scale %v (field `%s`)", fieldTag.Scale, field.Name)
+	}
+
+	spec := &scaleSpec{
+		scale:    fieldTag.Scale,
+		offset:   fieldTag.Offset,
+		saturate: fieldTag.Saturate,
+	}
+	if fieldTag.Round != "" {
+		mode, ok := roundModes[fieldTag.Round]
+		if !ok {
+			return fmt.Errorf("struc: invalid rounding `round=%s` (must be nearest, even, floor, ceil or trunc) (field `%s`)", fieldTag.Round, field.Name)
+		}
+		spec.round = mode
+	}
+	fieldDesc.scale = spec
+	return nil
+}
+
+// integerRange 返回整数线上类型的最小值和最大值（以 uint64 补码表示），以及对应的浮点边界
+// 浮点上界不含在范围内，避免 2^63 等无法精确表示的最大值
+func integerRange(t Type) (minValue, maxValue uint64, minFloat, maxFloat float64) {
+	bits := t.Size() * 8
+	if isSignedIntegerType(t) {
+		maxValue = 1<<(bits-1) - 1
+		minValue = ^maxValue
+		return minValue, maxValue, -math.Ldexp(1, bits-1), math.Ldexp(1, bits-1)
+	}
+	maxValue = 1<<bits - 1
+	return 0, maxValue, 0, math.Ldexp(1, bits)
+}
+
+// scaledRaw 将字段值转换为线上的整数值
+func (f *Field) scaledRaw(value float64, resolvedType Type) (uint64, error) {
+	if math.IsNaN(value) {
+		return 0, fmt.Errorf("struc: cannot pack NaN as scaled %s (field `%s`)", resolvedType, f.Name)
+	}
+	raw := f.scale.round.apply((value - f.scale.offset) / f.scale.scale)
+	minValue, maxValue, minFloat, maxFloat := integerRange(resolvedType)
+	switch {
+	case raw < minFloat:
+		if !f.scale.saturate {
+			return 0, fmt.Errorf("struc: %v is out of range for scaled %s (field `%s`)", value, resolvedType, f.Name)
+		}
+		return minValue, nil
+	case raw >= maxFloat:
+		if !f.scale.saturate {
+			return 0, fmt.Errorf("struc: %v is out of range for scaled %s (field `%s`)", value, resolvedType, f.Name)
+		}
+		return maxValue, nil
+	}
+	if isSignedIntegerType(resolvedType) {
+		return uint64(int64(raw)), nil
+	}
+	return uint64(raw), nil
+}
+
+// scaledValue 将线上的整数值转换为字段值
+func (f *Field) scaledValue(raw uint64, resolvedType Type) float64 {
+	var value float64
+	if isSignedIntegerType(resolvedType) {
+		value = float64(int64(raw))
+	} else {
+		value = float64(raw)
+	}
+	return value*f.scale.scale + f.scale.offset
+}
+
+// packScaled 打包缩放字段，支持单个值、切片和数组
+func (f *Field) packScaled(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, options *Options) (int, error) {
+	byteOrder := f.determineByteOrder(options)
+	elementSize := resolvedType.Size()
+
+	if !f.IsSlice {
+		if f.IsPointer {
+			fieldValue = fieldValue.Elem()
+		}
+		var value float64
+		if fieldValue.IsValid() {
+			value = fieldValue.Float()
+		}
+		return elementSize, f.writeScaled(buffer, value, resolvedType, byteOrder)
+	}
+
+	totalSize := length * elementSize
+	dataLength := fieldValue.Len()
+	if dataLength > length {
+		dataLength = length
+	}
+	for i := 0; i < dataLength; i++ {
+		if err := f.writeScaled(buffer[i*elementSize:], fieldValue.Index(i).Float(), resolvedType, byteOrder); err != nil {
+			return 0, fmt.Errorf("failed to pack slice element %d: %w", i, err)
+		}
+	}
+	if dataLength < length {
+		memclr(buffer[dataLength*elementSize : totalSize])
+	}
+	return totalSize, nil
+}
+
+// writeScaled 将单个缩放值写入缓冲区
+func (f *Field) writeScaled(buffer []byte, value float64, resolvedType Type, byteOrder binary.ByteOrder) error {
+	raw, err := f.scaledRaw(value, resolvedType)
+	if err != nil {
+		return err
+	}
+	return f.writeInteger(buffer, raw, resolvedType, byteOrder)
+}
+
+// unpackScaled 解包缩放字段
+func (f *Field) unpackScaled(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, options *Options) error {
+	byteOrder := f.determineByteOrder(options)
+	elementSize := resolvedType.Size()
+
+	if !f.IsSlice {
+		if f.IsPointer {
+			fieldValue = fieldValue.Elem()
+		}
+		fieldValue.SetFloat(f.scaledValue(f.readInteger(buffer, resolvedType, byteOrder), resolvedType))
+		return nil
+	}
+
+	if !f.IsArray {
+		if fieldValue.Cap() < length {
+			fieldValue.Set(reflect.MakeSlice(fieldValue.Type(), length, length))
+		} else if fieldValue.Len() != length {
+			fieldValue.Set(fieldValue.Slice(0, length))
+		}
+	}
+	if length > fieldValue.Len() {
+		length = fieldValue.Len()
+	}
+	for i := 0; i < length; i++ {
+		raw := f.readInteger(buffer[i*elementSize:], resolvedType, byteOrder)
+		fieldValue.Index(i).SetFloat(f.scaledValue(raw, resolvedType))
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package struc
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type scaleRecord struct {
+	Temp    float64    `struc:"int16,scale=0.01,offset=-40"`
+	Gain    float64    `struc:"q16.16"`
+	Sample  float32    `struc:"q15,little"`
+	Ratio   float64    `struc:"uq8.8"`
+	Speed   float64    `struc:"uint8,scale=0.5,round=floor"`
+	Clamped float64    `struc:"int8,scale=1,saturate"`
+	Levels  [2]float64 `struc:"[2]uint16,scale=0.1"`
+}
+
+func TestScaledFields(t *testing.T) {
+	in := &scaleRecord{
+		Temp:    21.5,
+		Gain:    -1.5,
+		Sample:  0.5,
+		Ratio:   1.25,
+		Speed:   10.9,
+		Clamped: 300,
+		Levels:  [2]float64{1.5, 2.5},
+	}
+	want := []byte{
+		0x18, 0x06, // (21.5+40)/0.01 = 6150
+		0xff, 0xfe, 0x80, 0x00, // -1.5 * 65536
+		0x00, 0x40, // 0.5 * 32768，小端序
+		0x01, 0x40, // 1.25 * 256
+		21,   // floor(10.9/0.5)
+		0x7f, // 300 截断到 127
+		0, 15, 0, 25,
+	}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), want)
+	}
+
+	out := &scaleRecord{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	expected := &scaleRecord{
+		Temp:    6150*0.01 - 40,
+		Gain:    -1.5,
+		Sample:  0.5,
+		Ratio:   1.25,
+		Speed:   10.5,
+		Clamped: 127,
+		Levels:  [2]float64{15 * 0.1, 25 * 0.1},
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Fatalf("unpack: got %+v, want %+v", out, expected)
+	}
+	if math.Abs(out.Temp-21.5) > 1e-9 {
+		t.Fatalf("temp: got %v", out.Temp)
+	}
+}
+
+func TestScaledRounding(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want byte
+	}{
+		{"nearest", &struct {
+			V float64 `struc:"int8,scale=1"`
+		}{2.5}, 3},
+		{"even", &struct {
+			V float64 `struc:"int8,scale=1,round=even"`
+		}{2.5}, 2},
+		{"floor", &struct {
+			V float64 `struc:"int8,scale=1,round=floor"`
+		}{2.7}, 2},
+		{"ceil", &struct {
+			V float64 `struc:"int8,scale=1,round=ceil"`
+		}{2.2}, 3},
+		{"trunc", &struct {
+			V float64 `struc:"int8,scale=1,round=trunc"`
+		}{-2.7}, 0xfe},
+		{"saturate low", &struct {
+			V float64 `struc:"int8,scale=1,saturate"`
+		}{-1000}, 0x80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Pack(&buf, tt.data); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.Bytes()[0]; got != tt.want {
+				t.Fatalf("got %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaledErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{"overflow", &struct {
+			V float64 `struc:"int8,scale=0.1"`
+		}{20}, "out of range for scaled int8"},
+		{"negative unsigned", &struct {
+			V float64 `struc:"uq8.8"`
+		}{-1}, "out of range for scaled uint16"},
+		{"nan", &struct {
+			V float64 `struc:"q16.16"`
+		}{math.NaN()}, "NaN"},
+		{"integer field", &struct {
+			V int32 `struc:"int16,scale=0.5"`
+		}{}, "must be a float32 or float64"},
+		{"float wire type", &struct {
+			V float64 `struc:"float32,scale=0.5"`
+		}{}, "integer wire type"},
+		{"zero scale", &struct {
+			V float64 `struc:"int16,scale=0"`
+		}{}, "invalid scale"},
+		{"q format with scale", &struct {
+			V float64 `struc:"q16.16,scale=2"`
+		}{}, "conflicts"},
+		{"saturate without scale", &struct {
+			V int16 `struc:"int16,saturate"`
+		}{}, "require `scale=`"},
+		{"bad rounding", &struct {
+			V float64 `struc:"int16,scale=2,round=up"`
+		}{}, "invalid rounding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Pack(&bytes.Buffer{}, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package struc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// 字段路径与外层结构体引用
+//
+// sizeof= 和 sizefrom= 除了当前结构体的字段名，还可以引用：
+//
+//   - 嵌套结构体中的字段：sizefrom=Header.Length
+//   - 外层结构体中的字段：sizefrom=../Count、sizeof=../../Payload
+//   - 匿名嵌入结构体的提升字段：嵌入结构体按其字段平铺在外层结构体中，
+//     嵌入结构体内的标签可以直接使用外层结构体的字段名，外层结构体也可以直接使用提升字段名
+//
+// 路径在解析时转换为字段索引；引用外层结构体的嵌套结构体依赖其外层结构体，不能单独打包。
+
+// parentPrefix 是外层结构体引用的前缀
+const parentPrefix = "../"
+
+// parseScope 是解析嵌套结构体时外层结构体的信息
+type parseScope struct {
+	structType reflect.Type     // 外层结构体类型
+	name       string           // 嵌套结构体字段在外层结构体中的名称
+	index      []int            // 嵌套结构体字段的索引，结构体切片和指针为 nil
+	embedded   bool             // 嵌套结构体是否为匿名嵌入（名称可回退到外层结构体解析）
+	sizeofMap  map[string][]int // 外层结构体中 sizeof 字段的目标
+	up         *parseScope      // 更外层的结构体
+}
+
+// structScope 是打包和解包时外层结构体值的链，通过 Options 传递给嵌套结构体
+type structScope struct {
+	value reflect.Value
+	up    *structScope
+}
+
+// isFieldPath 判断引用是否为路径（含 . 或 ../），而非当前结构体的字段名
+func isFieldPath(path string) bool {
+	return strings.Contains(path, ".")
+}
+
+// isFieldRef 判断 sizefrom= 的值是否为字段名或字段路径，而非长度表达式
+func isFieldRef(path string) bool {
+	for strings.HasPrefix(path, parentPrefix) {
+		path = path[len(parentPrefix):]
+	}
+	for _, name := range strings.Split(path, ".") {
+		if !isExprIdent(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveFieldPath 将字段路径解析为索引
+// 返回路径末端的字段、完整索引和所在的外层层数（0 表示当前结构体）
+func resolveFieldPath(structType reflect.Type, path string, scope *parseScope) (reflect.StructField, []int, int, error) {
+	up := 0
+	for strings.HasPrefix(path, parentPrefix) {
+		if scope == nil {
+			return reflect.StructField{}, nil, 0, fmt.Errorf("struc: `%s` has no enclosing struct", path)
+		}
+		path = path[len(parentPrefix):]
+		structType = scope.structType
+		scope = scope.up
+		up++
+	}
+
+	field, index, err := lookupFieldPath(structType, path)
+	// 匿名嵌入结构体中找不到的名称在外层结构体中查找
+	if err != nil && up == 0 && scope != nil && scope.embedded {
+		if outerField, outerIndex, outerErr := lookupFieldPath(scope.structType, path); outerErr == nil {
+			return outerField, outerIndex, 1, nil
+		}
+	}
+	return field, index, up, err
+}
+
+// lookupFieldPath 按 . 分隔的路径逐级查找字段
+func lookupFieldPath(structType reflect.Type, path string) (reflect.StructField, []int, error) {
+	var field reflect.StructField
+	var index []int
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct {
+			return field, nil, fmt.Errorf("struc: `%s` in path `%s` is not a struct", names[i-1], path)
+		}
+		var ok bool
+		if field, ok = structType.FieldByName(name); !ok {
+			return field, nil, fmt.Errorf("struc: field `%s` in path `%s` does not exist", name, path)
+		}
+		if i < len(names)-1 && field.Type.Kind() == reflect.Ptr {
+			return field, nil, fmt.Errorf("struc: path `%s` cannot go through pointer field `%s`", path, name)
+		}
+		index = append(index, field.Index...)
+		structType = field.Type
+	}
+	return field, index, nil
+}
+
+// exportSizeof 将指向外层结构体字段的 sizeof 登记到外层结构体，使被引用的字段自动获得长度来源
+func exportSizeof(scope *parseScope, up int, target reflect.StructField, field reflect.StructField) {
+	if scope == nil || up != 1 || scope.index == nil || len(target.Index) != 1 {
+		return
+	}
+	source := make([]int, 0, len(scope.index)+len(field.Index))
+	source = append(source, scope.index...)
+	scope.sizeofMap[target.Name] = append(source, field.Index...)
+}
+
+// importSizefrom 查找外层结构体中以该字段为目标的 sizeof 字段
+func importSizefrom(scope *parseScope, field reflect.StructField) ([]int, bool) {
+	if scope == nil {
+		return nil, false
+	}
+	if sizefrom, ok := scope.sizeofMap[scope.name+"."+field.Name]; ok {
+		return sizefrom, true
+	}
+	if scope.embedded {
+		sizefrom, ok := scope.sizeofMap[field.Name]
+		return sizefrom, ok
+	}
+	return nil, false
+}
+
+// needsParseScope 判断嵌套结构体是否需要在外层结构体的上下文中解析：
+// 匿名嵌入的结构体，或者自身及其嵌套结构体的标签中引用了外层结构体
+func needsParseScope(field reflect.StructField, structType reflect.Type) bool {
+	return field.Anonymous || hasParentRefs(structType, map[reflect.Type]bool{})
+}
+
+// hasParentRefs 递归检查结构体的标签中是否有 ../ 引用
+func hasParentRefs(structType reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[structType] {
+		return false
+	}
+	visited[structType] = true
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get("struc")
+		if tag == "" {
+			tag = field.Tag.Get("struct")
+		}
+		if strings.Contains(tag, parentPrefix) {
+			return true
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr || fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && hasParentRefs(fieldType, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesScope 判断字段集合在运行时是否需要外层结构体的值
+func (f Fields) usesScope() bool {
+	for _, field := range f {
+		if field != nil && (field.sizeofUp > 0 || field.sizefromUp > 0 || field.scoped) {
+			return true
+		}
+	}
+	return false
+}
+
+// enter 返回进入嵌套结构体时使用的选项，structValue 为当前（外层）结构体
+func (o *Options) enter(structValue reflect.Value) *Options {
+	nested := *o
+	nested.scope = &structScope{value: structValue, up: o.scope}
+	return &nested
+}
+
+// ancestor 返回向外 up 层的结构体值
+func (o *Options) ancestor(up int) reflect.Value {
+	scope := o.scope
+	for ; up > 1 && scope != nil; up-- {
+		scope = scope.up
+	}
+	if scope == nil {
+		panic("struc: struct refers to an enclosing struct that is not being packed or unpacked")
+	}
+	return scope.value
+}
+
+// refBase 返回引用所在的结构体：up 为 0 时为当前结构体，否则为外层结构体
+func refBase(structValue reflect.Value, up int, options *Options) reflect.Value {
+	if up == 0 {
+		return structValue
+	}
+	return options.ancestor(up)
+}
@@ -0,0 +1,137 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type scopeHeader struct {
+	Kind   uint8
+	Length uint16
+}
+
+type scopeDotted struct {
+	Header  scopeHeader
+	Payload []byte `struc:"[]byte,sizefrom=Header.Length"`
+}
+
+type scopeItem struct {
+	Tags []byte `struc:"[]byte,sizefrom=../TagLen"`
+}
+
+type scopeParent struct {
+	TagLen uint8
+	Count  uint8 `struc:"uint8,sizeof=Items"`
+	Items  []scopeItem
+}
+
+type ScopeEmbeddedHeader struct {
+	Size uint8 `struc:"uint8,sizeof=Payload"`
+}
+
+type scopeEmbedded struct {
+	ScopeEmbeddedHeader
+	Payload []byte
+}
+
+type scopePromoted struct {
+	Count uint8 `struc:"uint8,sizeof=Items"`
+	ScopeItems
+}
+
+type ScopeItems struct {
+	Items []uint16
+}
+
+func scopeRoundTrip(t *testing.T, in, out interface{}, want []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), want)
+	}
+	size, err := Sizeof(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != len(want) {
+		t.Fatalf("sizeof: got %d, want %d", size, len(want))
+	}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestScopeDottedPath(t *testing.T) {
+	in := &scopeDotted{Header: scopeHeader{Kind: 1, Length: 3}, Payload: []byte{7, 8, 9}}
+	scopeRoundTrip(t, in, &scopeDotted{}, []byte{1, 0, 3, 7, 8, 9})
+}
+
+func TestScopeParentRef(t *testing.T) {
+	in := &scopeParent{
+		TagLen: 2,
+		Count:  2,
+		Items:  []scopeItem{{Tags: []byte{1, 2}}, {Tags: []byte{3, 4}}},
+	}
+	scopeRoundTrip(t, in, &scopeParent{}, []byte{2, 2, 1, 2, 3, 4})
+}
+
+func TestScopeEmbedded(t *testing.T) {
+	// 嵌入结构体中的 sizeof 引用外层结构体的字段，打包时自动填写
+	in := &scopeEmbedded{Payload: []byte{5, 6}}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{2, 5, 6}; !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), want)
+	}
+	out := &scopeEmbedded{}
+	if err := Unpack(bytes.NewReader(buf.Bytes()), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Size != 2 || !bytes.Equal(out.Payload, []byte{5, 6}) {
+		t.Fatalf("unpack: got %+v", out)
+	}
+
+	// 外层结构体的 sizeof 引用嵌入结构体的提升字段
+	promoted := &scopePromoted{Count: 2, ScopeItems: ScopeItems{Items: []uint16{0x0102, 0x0304}}}
+	scopeRoundTrip(t, promoted, &scopePromoted{}, []byte{2, 1, 2, 3, 4})
+}
+
+func TestScopeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{"no enclosing struct", &scopeItem{}, "has no enclosing struct"},
+		{"missing path segment", &struct {
+			Header  scopeHeader
+			Payload []byte `struc:"[]byte,sizefrom=Header.Missing"`
+		}{}, "field `Missing` in path `Header.Missing` does not exist"},
+		{"path through non-struct", &struct {
+			Len     uint8
+			Payload []byte `struc:"[]byte,sizefrom=Len.Value"`
+		}{}, "is not a struct"},
+		{"non-integer source", &struct {
+			Header  scopeDotted
+			Payload []byte `struc:"[]byte,sizefrom=Header.Payload"`
+		}{}, "must refer to an integer field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sizeof(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package struc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// 顶层结构体切片和数组
+//
+// Pack、Unpack 和 Sizeof 可以直接处理带 struc 标签的结构体切片和数组，
+// 元素依次按结构体的字段打包，复用结构体类型缓存的 fieldsPacker：
+//
+//	records := []Record{...}
+//	struc.Pack(w, records)
+//
+//	var out []Record
+//	struc.Unpack(r, &out) // 依次解包直到输入结束
+//
+// 解包时数组和长度非零的切片按其长度解包相应个数的元素；
+// 长度为 0 的切片需要传入指针，依次解包元素直到输入结束。
+// 元素之间没有填充，每个元素的大小与单独打包时相同。
+
+// structSlicePacker 是结构体切片和数组的打包器
+type structSlicePacker struct {
+	sliceType reflect.Type // 切片或数组类型
+	elem      Packer       // 元素结构体的打包器
+}
+
+// isStructSequence 判断类型是否为结构体切片或数组
+func isStructSequence(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Struct
+}
+
+// parseStructSlicePacker 获取结构体切片或数组的打包器，元素的字段解析结果按结构体类型缓存
+func parseStructSlicePacker(value reflect.Value) (Packer, error) {
+	sliceType := value.Type()
+	elem, err := parseFieldsPacker(reflect.New(sliceType.Elem()).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &structSlicePacker{sliceType: sliceType, elem: elem}, nil
+}
+
+// String 返回切片类型的字符串表示
+func (p *structSlicePacker) String() string {
+	return p.sliceType.String()
+}
+
+// Sizeof 返回所有元素打包后的总字节数
+func (p *structSlicePacker) Sizeof(value reflect.Value, options *Options) int {
+	size := 0
+	for i := 0; i < value.Len(); i++ {
+		size += p.elem.Sizeof(value.Index(i), options)
+	}
+	return size
+}
+
+// Pack 依次打包所有元素
+func (p *structSlicePacker) Pack(buffer []byte, value reflect.Value, options *Options) (int, error) {
+	position := 0
+	for i := 0; i < value.Len(); i++ {
+		n, err := p.elem.Pack(buffer[position:], value.Index(i), options)
+		if err != nil {
+			return position, fmt.Errorf("%w (element %d)", err, i)
+		}
+		position += n
+	}
+	return position, nil
+}
+
+// Unpack 按长度解包元素，长度为 0 的切片解包到输入结束
+func (p *structSlicePacker) Unpack(reader io.Reader, value reflect.Value, options *Options) error {
+	if value.Kind() == reflect.Array || value.Len() > 0 {
+		if value.Len() > 0 && !value.Index(0).CanSet() {
+			return fmt.Errorf("struc: cannot unpack into %s; pass a pointer to it", p.sliceType)
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := p.elem.Unpack(reader, value.Index(i), options); err != nil {
+				return fmt.Errorf("%w (element %d)", err, i)
+			}
+		}
+		return nil
+	}
+
+	if !value.CanSet() {
+		return fmt.Errorf("struc: unpacking %s until the end of input requires a pointer to the slice", p.sliceType)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	return p.unpackAll(data, value, options)
+}
+
+// unpackAll 依次解包结构体元素，直到剩余字节耗尽
+func (p *structSlicePacker) unpackAll(data []byte, value reflect.Value, options *Options) error {
+	reader := bytes.NewReader(data)
+	sliceValue := reflect.MakeSlice(p.sliceType, 0, 0)
+
+	for reader.Len() > 0 {
+		remaining := reader.Len()
+		elementValue := reflect.New(p.sliceType.Elem()).Elem()
+		if err := p.elem.Unpack(reader, elementValue, options); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return fmt.Errorf("struc: %d trailing bytes do not form a whole element of %s", remaining, p.sliceType)
+			}
+			return fmt.Errorf("%w (element %d)", err, sliceValue.Len())
+		}
+		if reader.Len() == remaining {
+			return fmt.Errorf("struc: elements of %s consume no input", p.sliceType)
+		}
+		sliceValue = reflect.Append(sliceValue, elementValue)
+	}
+
+	value.Set(sliceValue)
+	return nil
+}
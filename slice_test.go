@@ -0,0 +1,105 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type sliceRecord struct {
+	Size uint8 `struc:"uint8,sizeof=Name"`
+	Name string
+	ID   uint16 `struc:"uint16,little"`
+}
+
+func sliceRecords() []sliceRecord {
+	return []sliceRecord{
+		{Size: 2, Name: "ab", ID: 0x0102},
+		{Size: 1, Name: "c", ID: 0x0304},
+	}
+}
+
+var sliceRecordsData = []byte{2, 'a', 'b', 2, 1, 1, 'c', 4, 3}
+
+func TestStructSlicePack(t *testing.T) {
+	in := sliceRecords()
+
+	size, err := Sizeof(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != len(sliceRecordsData) {
+		t.Fatalf("sizeof: got %d, want %d", size, len(sliceRecordsData))
+	}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), sliceRecordsData) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), sliceRecordsData)
+	}
+
+	// 指针和数组与切片的结果相同
+	buf.Reset()
+	array := [2]sliceRecord(in)
+	if err := Pack(&buf, &array); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), sliceRecordsData) {
+		t.Fatalf("pack array: got %x, want %x", buf.Bytes(), sliceRecordsData)
+	}
+}
+
+func TestStructSliceUnpack(t *testing.T) {
+	want := sliceRecords()
+
+	// 长度为 0 的切片解包到输入结束
+	var all []sliceRecord
+	if err := Unpack(bytes.NewReader(sliceRecordsData), &all); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(all, want) {
+		t.Fatalf("until EOF: got %+v, want %+v", all, want)
+	}
+
+	// 长度非零的切片只解包相应个数的元素
+	first := make([]sliceRecord, 1)
+	reader := bytes.NewReader(sliceRecordsData)
+	if err := Unpack(reader, first); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, want[:1]) || reader.Len() != 4 {
+		t.Fatalf("count: got %+v with %d bytes left", first, reader.Len())
+	}
+
+	var array [2]sliceRecord
+	if err := Unpack(bytes.NewReader(sliceRecordsData), &array); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(array[:], want) {
+		t.Fatalf("array: got %+v, want %+v", array, want)
+	}
+}
+
+func TestStructSliceErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		in   []byte
+		want string
+	}{
+		{"trailing bytes", &[]sliceRecord{}, append(sliceRecordsData, 3, 'x'), "trailing bytes"},
+		{"until EOF without pointer", []sliceRecord{}, sliceRecordsData, "requires a pointer"},
+		{"array without pointer", [1]sliceRecord{}, sliceRecordsData, "pass a pointer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unpack(bytes.NewReader(tt.in), tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -175,10 +175,10 @@ func prepareValueForPacking(data interface{}) (reflect.Value, Packer, error) {
 
 	value := reflect.ValueOf(data)
 
-	// 解引用指针直到获取非指针类型
+	// 解引用指针直到获取非指针类型，结构体切片和数组的指针用于解包时设置元素
 	for value.Kind() == reflect.Ptr {
 		next := value.Elem().Kind()
-		if next == reflect.Struct || next == reflect.Ptr {
+		if next == reflect.Struct || next == reflect.Ptr || isStructSequence(value.Type().Elem()) {
 			value = value.Elem()
 		} else {
 			break
@@ -200,6 +200,12 @@ func prepareValueForPacking(data interface{}) (reflect.Value, Packer, error) {
 		}
 		if customPacker, ok := data.(CustomBinaryer); ok {
 			packer = customBinaryerFallback{customPacker}
+		} else if isStructSequence(value.Type()) {
+			slicePacker, err := parseStructSlicePacker(value)
+			if err != nil {
+				return reflect.Value{}, nil, fmt.Errorf("failed to parse fields: %w", err)
+			}
+			packer = slicePacker
 		} else {
 			packer = binaryFallback(value)
 		}
@@ -0,0 +1,343 @@
+package struc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// 时间戳编码
+//
+// time.Time 和 time.Duration 字段（及其指针、切片和数组）可以通过标签选择线上的时间编码：
+//
+//	unix32      uint32，自 1970-01-01 起的秒数
+//	unix64      int64，自 1970-01-01 起的秒数
+//	unixms64    int64，自 1970-01-01 起的毫秒数
+//	ntp64       NTP 时间戳：uint32 秒数（自 1900-01-01 起）+ uint32 秒的小数部分
+//	filetime    Windows FILETIME：uint64，自 1601-01-01 起的 100 纳秒数
+//	dosdatetime MS-DOS 日期时间：uint32，高 16 位为日期，低 16 位为时间，精度 2 秒
+//	gpsweek     GPS 周数 uint16 + 周内秒数 uint32，自 1980-01-06 起，不做闰秒修正
+//
+// 打包时时间先转换为 UTC，超出编码范围时报错，低于编码精度的部分被截断；解包得到 UTC 时间。
+// ntp64、filetime 和 dosdatetime 无法表示零值 time.Time，零值与全零的线上值相互对应；
+// 其余编码的全零值就是纪元本身，零值 time.Time 按实际时间编码。ntp64 和 gpsweek 的两部分各自遵循字段的字节序，
+// dosdatetime 按小端序保存时与 ZIP 中先时间后日期的格式一致。
+//
+// time.Duration 字段按相同的单位保存时长（unix32、unix64、unixms64、ntp64、filetime），
+// 不使用纪元；dosdatetime 和 gpsweek 只能用于 time.Time。
+
+var (
+	timeGoType     = reflect.TypeOf(time.Time{})
+	durationGoType = reflect.TypeOf(time.Duration(0))
+)
+
+const (
+	// ntpEpochOffset 是 1900-01-01 到 1970-01-01 的秒数
+	ntpEpochOffset = 2208988800
+	// filetimeEpochOffset 是 1601-01-01 到 1970-01-01 的秒数
+	filetimeEpochOffset = 11644473600
+	// gpsEpochUnix 是 GPS 纪元 1980-01-06 的 Unix 秒数
+	gpsEpochUnix = 315964800
+	// secondsPerWeek 是一周的秒数
+	secondsPerWeek = 7 * 24 * 3600
+)
+
+// IsTime 判断是否为时间戳类型
+func (t Type) IsTime() bool {
+	switch t {
+	case Unix32, Unix64, UnixMs64, NTP64, Filetime, DOSDateTime, GPSWeek:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleTimeTag 校验时间戳字段的 Go 类型
+func handleTimeTag(fieldDesc *Field, field reflect.StructField) error {
+	if !fieldDesc.Type.IsTime() {
+		return nil
+	}
+	goType := field.Type
+	switch goType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		goType = goType.Elem()
+	}
+	switch goType {
+	case timeGoType:
+		return nil
+	case durationGoType:
+		if fieldDesc.Type == DOSDateTime || fieldDesc.Type == GPSWeek {
+			return fmt.Errorf("struc: %s field `%s` must be a time.Time, got %v", fieldDesc.Type, field.Name, field.Type)
+		}
+		return nil
+	}
+	return fmt.Errorf("struc: %s field `%s` must be a time.Time or time.Duration, got %v", fieldDesc.Type, field.Name, field.Type)
+}
+
+// hasZeroTimeSentinel 判断编码是否用全零的线上值表示零值 time.Time
+// 只适用于零值本身超出范围的编码，unix 和 gpsweek 的全零值是有效的纪元时间
+func hasZeroTimeSentinel(resolvedType Type) bool {
+	switch resolvedType {
+	case NTP64, Filetime, DOSDateTime:
+		return true
+	default:
+		return false
+	}
+}
+
+// encodeTime 将时间转换为线上的整数值，超出编码范围时返回 false
+func encodeTime(t time.Time, resolvedType Type) (uint64, bool) {
+	if t.IsZero() && hasZeroTimeSentinel(resolvedType) {
+		return 0, true
+	}
+	t = t.UTC()
+	seconds, nanos := t.Unix(), int64(t.Nanosecond())
+
+	switch resolvedType {
+	case Unix32:
+		if seconds < 0 || seconds > math.MaxUint32 {
+			return 0, false
+		}
+		return uint64(seconds), true
+	case Unix64:
+		return uint64(seconds), true
+	case UnixMs64:
+		if seconds > math.MaxInt64/1000-1 || seconds < math.MinInt64/1000+1 {
+			return 0, false
+		}
+		return uint64(seconds*1000 + nanos/1e6), true
+	case NTP64:
+		seconds += ntpEpochOffset
+		if seconds < 0 || seconds > math.MaxUint32 {
+			return 0, false
+		}
+		return uint64(seconds)<<32 | uint64(nanos)<<32/1e9, true
+	case Filetime:
+		seconds += filetimeEpochOffset
+		if seconds < 0 || seconds > math.MaxInt64/10000000-1 {
+			return 0, false
+		}
+		return uint64(seconds)*1e7 + uint64(nanos)/100, true
+	case DOSDateTime:
+		if t.Year() < 1980 || t.Year() > 2107 {
+			return 0, false
+		}
+		date := uint64(t.Year()-1980)<<9 | uint64(t.Month())<<5 | uint64(t.Day())
+		clock := uint64(t.Hour())<<11 | uint64(t.Minute())<<5 | uint64(t.Second()/2)
+		return date<<16 | clock, true
+	case GPSWeek:
+		seconds -= gpsEpochUnix
+		if seconds < 0 || seconds/secondsPerWeek > math.MaxUint16 {
+			return 0, false
+		}
+		return uint64(seconds/secondsPerWeek)<<32 | uint64(seconds%secondsPerWeek), true
+	}
+	return 0, false
+}
+
+// decodeTime 将线上的整数值转换为 UTC 时间
+func decodeTime(raw uint64, resolvedType Type) time.Time {
+	if raw == 0 && hasZeroTimeSentinel(resolvedType) {
+		return time.Time{}
+	}
+
+	var t time.Time
+	switch resolvedType {
+	case Unix32, Unix64:
+		t = time.Unix(int64(raw), 0)
+	case UnixMs64:
+		t = time.UnixMilli(int64(raw))
+	case NTP64:
+		t = time.Unix(int64(raw>>32)-ntpEpochOffset, int64(ntpFractionNanos(raw)))
+	case Filetime:
+		t = time.Unix(int64(raw/1e7)-filetimeEpochOffset, int64(raw%1e7)*100)
+	case DOSDateTime:
+		date, clock := int(raw>>16), int(raw&0xffff)
+		t = time.Date(1980+date>>9, time.Month(date>>5&0x0f), date&0x1f,
+			clock>>11, clock>>5&0x3f, clock&0x1f*2, 0, time.UTC)
+	case GPSWeek:
+		t = time.Unix(gpsEpochUnix+int64(raw>>32)*secondsPerWeek+int64(raw&0xffffffff), 0)
+	}
+	return t.UTC()
+}
+
+// ntpFractionNanos 将 NTP 秒的小数部分转换为纳秒，向上取整以保证打包后能还原
+func ntpFractionNanos(raw uint64) uint64 {
+	return ((raw&0xffffffff)*1e9 + 1<<32 - 1) >> 32
+}
+
+// encodeDuration 将时长转换为线上的整数值，超出编码范围时返回 false
+func encodeDuration(d time.Duration, resolvedType Type) (uint64, bool) {
+	switch resolvedType {
+	case Unix32:
+		seconds := int64(d / time.Second)
+		if seconds < 0 || seconds > math.MaxUint32 {
+			return 0, false
+		}
+		return uint64(seconds), true
+	case Unix64:
+		return uint64(d / time.Second), true
+	case UnixMs64:
+		return uint64(d / time.Millisecond), true
+	case NTP64:
+		if d < 0 || d/time.Second > math.MaxUint32 {
+			return 0, false
+		}
+		return uint64(d/time.Second)<<32 | uint64(d%time.Second)<<32/1e9, true
+	case Filetime:
+		return uint64(d / 100), true
+	}
+	return 0, false
+}
+
+// decodeDuration 将线上的整数值转换为时长，超出 time.Duration 范围时返回 false
+func decodeDuration(raw uint64, resolvedType Type) (time.Duration, bool) {
+	var value, unit int64
+	switch resolvedType {
+	case Unix32:
+		value, unit = int64(raw), int64(time.Second)
+	case Unix64:
+		value, unit = int64(raw), int64(time.Second)
+	case UnixMs64:
+		value, unit = int64(raw), int64(time.Millisecond)
+	case NTP64:
+		value, unit = int64(raw>>32), int64(time.Second)
+	case Filetime:
+		value, unit = int64(raw), 100
+	default:
+		return 0, false
+	}
+	if value > math.MaxInt64/unit || value < math.MinInt64/unit {
+		return 0, false
+	}
+	d := time.Duration(value * unit)
+	if resolvedType == NTP64 {
+		d += time.Duration(ntpFractionNanos(raw))
+	}
+	return d, true
+}
+
+// writeTimeRaw 按字节序写入时间戳，ntp64 和 gpsweek 的两部分分别写入
+func writeTimeRaw(buffer []byte, raw uint64, resolvedType Type, byteOrder binary.ByteOrder) {
+	switch resolvedType {
+	case NTP64:
+		putUintN(buffer, raw>>32, 4, byteOrder)
+		putUintN(buffer[4:], raw&0xffffffff, 4, byteOrder)
+	case GPSWeek:
+		putUintN(buffer, raw>>32, 2, byteOrder)
+		putUintN(buffer[2:], raw&0xffffffff, 4, byteOrder)
+	default:
+		putUintN(buffer, raw, resolvedType.Size(), byteOrder)
+	}
+}
+
+// readTimeRaw 按字节序读取时间戳
+func readTimeRaw(buffer []byte, resolvedType Type, byteOrder binary.ByteOrder) uint64 {
+	switch resolvedType {
+	case NTP64:
+		return getUintN(buffer, 4, byteOrder)<<32 | getUintN(buffer[4:], 4, byteOrder)
+	case GPSWeek:
+		return getUintN(buffer, 2, byteOrder)<<32 | getUintN(buffer[2:], 4, byteOrder)
+	default:
+		return getUintN(buffer, resolvedType.Size(), byteOrder)
+	}
+}
+
+// timeRaw 将 time.Time 或 time.Duration 值转换为线上的整数值，nil 指针视为零值
+func (f *Field) timeRaw(value reflect.Value, resolvedType Type) (uint64, error) {
+	if !value.IsValid() {
+		return 0, nil
+	}
+	var raw uint64
+	var ok bool
+	if value.Type() == durationGoType {
+		raw, ok = encodeDuration(time.Duration(value.Int()), resolvedType)
+	} else {
+		raw, ok = encodeTime(value.Interface().(time.Time), resolvedType)
+	}
+	if !ok {
+		return 0, fmt.Errorf("struc: %v is out of range for %s (field `%s`)", value.Interface(), resolvedType, f.Name)
+	}
+	return raw, nil
+}
+
+// setTime 将线上的整数值写入 time.Time 或 time.Duration 值
+func (f *Field) setTime(value reflect.Value, raw uint64, resolvedType Type) error {
+	if value.Type() != durationGoType {
+		value.Set(reflect.ValueOf(decodeTime(raw, resolvedType)))
+		return nil
+	}
+	d, ok := decodeDuration(raw, resolvedType)
+	if !ok {
+		return fmt.Errorf("struc: %s value %d overflows time.Duration (field `%s`)", resolvedType, raw, f.Name)
+	}
+	value.SetInt(int64(d))
+	return nil
+}
+
+// packTime 打包时间戳字段，支持单个值、切片和数组
+func (f *Field) packTime(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, options *Options) (int, error) {
+	byteOrder := f.determineByteOrder(options)
+	elementSize := resolvedType.Size()
+
+	if !f.IsSlice {
+		if f.IsPointer {
+			fieldValue = fieldValue.Elem()
+		}
+		raw, err := f.timeRaw(fieldValue, resolvedType)
+		if err != nil {
+			return 0, err
+		}
+		writeTimeRaw(buffer, raw, resolvedType, byteOrder)
+		return elementSize, nil
+	}
+
+	totalSize := length * elementSize
+	dataLength := fieldValue.Len()
+	if dataLength > length {
+		dataLength = length
+	}
+	for i := 0; i < dataLength; i++ {
+		raw, err := f.timeRaw(fieldValue.Index(i), resolvedType)
+		if err != nil {
+			return 0, fmt.Errorf("failed to pack slice element %d: %w", i, err)
+		}
+		writeTimeRaw(buffer[i*elementSize:], raw, resolvedType, byteOrder)
+	}
+	if dataLength < length {
+		memclr(buffer[dataLength*elementSize : totalSize])
+	}
+	return totalSize, nil
+}
+
+// unpackTime 解包时间戳字段
+func (f *Field) unpackTime(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type, options *Options) error {
+	byteOrder := f.determineByteOrder(options)
+	elementSize := resolvedType.Size()
+
+	if !f.IsSlice {
+		if f.IsPointer {
+			fieldValue = fieldValue.Elem()
+		}
+		return f.setTime(fieldValue, readTimeRaw(buffer, resolvedType, byteOrder), resolvedType)
+	}
+
+	if !f.IsArray {
+		if fieldValue.Cap() < length {
+			fieldValue.Set(reflect.MakeSlice(fieldValue.Type(), length, length))
+		} else if fieldValue.Len() != length {
+			fieldValue.Set(fieldValue.Slice(0, length))
+		}
+	}
+	if length > fieldValue.Len() {
+		length = fieldValue.Len()
+	}
+	for i := 0; i < length; i++ {
+		if err := f.setTime(fieldValue.Index(i), readTimeRaw(buffer[i*elementSize:], resolvedType, byteOrder), resolvedType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
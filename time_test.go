@@ -0,0 +1,192 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type timeRecord struct {
+	Unix     time.Time     `struc:"unix32"`
+	Unix64   time.Time     `struc:"unix64"`
+	Millis   time.Time     `struc:"unixms64"`
+	NTP      time.Time     `struc:"ntp64"`
+	File     time.Time     `struc:"filetime,little"`
+	DOS      time.Time     `struc:"dosdatetime,little"`
+	GPS      time.Time     `struc:"gpsweek"`
+	Timeout  time.Duration `struc:"unixms64"`
+	Interval time.Duration `struc:"ntp64"`
+	Stamps   [2]time.Time  `struc:"[2]unix32"`
+	Optional *time.Time    `struc:"unix32"`
+}
+
+func TestTimeEncodings(t *testing.T) {
+	moment := time.Date(2024, 2, 29, 12, 34, 56, 123456789, time.UTC)
+	local := moment.In(time.FixedZone("UTC+8", 8*3600))
+	optional := moment.Truncate(time.Second)
+
+	in := &timeRecord{
+		Unix:     local,
+		Unix64:   moment,
+		Millis:   moment,
+		NTP:      moment,
+		File:     moment,
+		DOS:      moment,
+		GPS:      moment,
+		Timeout:  1500 * time.Millisecond,
+		Interval: 2*time.Second + 250*time.Millisecond,
+		Stamps:   [2]time.Time{moment, time.Unix(0, 0)},
+		Optional: &optional,
+	}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if size, err := Sizeof(in); err != nil || size != len(data) || size != 4+8+8+8+8+4+6+8+8+8+4 {
+		t.Fatalf("sizeof: got %d (%v), packed %d bytes", size, err, len(data))
+	}
+
+	// 2024-02-29 12:34:56 UTC
+	if want := []byte{0x65, 0xe0, 0x79, 0xf0}; !bytes.Equal(data[:4], want) {
+		t.Fatalf("unix32: got %x, want %x", data[:4], want)
+	}
+	// FILETIME 133536836961234567，小端序
+	if want := []byte{0x87, 0xee, 0x80, 0xb3, 0x0b, 0x6b, 0xda, 0x01}; !bytes.Equal(data[28:36], want) {
+		t.Fatalf("filetime: got %x, want %x", data[28:36], want)
+	}
+	// DOS 时间 12:34:56 为 0x645c，日期 2024-02-29 为 0x585d，ZIP 中先时间后日期
+	if want := []byte{0x5c, 0x64, 0x5d, 0x58}; !bytes.Equal(data[36:40], want) {
+		t.Fatalf("dosdatetime: got %x, want %x", data[36:40], want)
+	}
+	// GPS 第 2303 周，周内 390896 秒（不含闰秒）
+	if want := []byte{0x08, 0xff, 0x00, 0x05, 0xf6, 0xf0}; !bytes.Equal(data[40:46], want) {
+		t.Fatalf("gpsweek: got %x, want %x", data[40:46], want)
+	}
+
+	out := &timeRecord{Optional: new(time.Time)}
+	if err := Unpack(bytes.NewReader(data), out); err != nil {
+		t.Fatal(err)
+	}
+	want := &timeRecord{
+		Unix:     moment.Truncate(time.Second),
+		Unix64:   moment.Truncate(time.Second),
+		Millis:   moment.Truncate(time.Millisecond),
+		NTP:      moment,
+		File:     moment.Truncate(100 * time.Nanosecond),
+		DOS:      moment.Truncate(2 * time.Second),
+		GPS:      moment.Truncate(time.Second),
+		Timeout:  in.Timeout,
+		Interval: in.Interval,
+		Stamps:   [2]time.Time{moment.Truncate(time.Second), time.Unix(0, 0).UTC()},
+		Optional: &optional,
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Fatalf("round trip:\n got %+v\nwant %+v", out, want)
+	}
+}
+
+func TestTimeZero(t *testing.T) {
+	// unix 和 gpsweek 的全零值是纪元本身，解包后不能变成零值 time.Time
+	epochs := &struct {
+		Unix   time.Time `struc:"unix32"`
+		Unix64 time.Time `struc:"unix64"`
+		Millis time.Time `struc:"unixms64"`
+		GPS    time.Time `struc:"gpsweek"`
+	}{time.Unix(0, 0), time.Unix(0, 0), time.Unix(0, 0), time.Unix(gpsEpochUnix, 0)}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, epochs); err != nil {
+		t.Fatal(err)
+	}
+	if want := make([]byte, 4+8+8+6); !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("epochs: got % x, want % x", buf.Bytes(), want)
+	}
+	out := *epochs
+	out.Unix, out.Unix64, out.Millis, out.GPS = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+	if err := Unpack(bytes.NewReader(buf.Bytes()), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Unix.Equal(epochs.Unix) || !out.Unix64.Equal(epochs.Unix64) ||
+		!out.Millis.Equal(epochs.Millis) || !out.GPS.Equal(epochs.GPS) {
+		t.Fatalf("epochs round trip: got %+v", out)
+	}
+
+	// 零值 time.Time 在 unix64 中按实际时间编码
+	zero := &struct {
+		Unix64 time.Time `struc:"unix64"`
+	}{}
+	buf.Reset()
+	if err := Pack(&buf, zero); err != nil {
+		t.Fatal(err)
+	}
+	zero.Unix64 = time.Unix(1, 0)
+	if err := Unpack(bytes.NewReader(buf.Bytes()), zero); err != nil || !zero.Unix64.IsZero() {
+		t.Fatalf("unix64 zero time: got %v (%v)", zero.Unix64, err)
+	}
+
+	// ntp64、filetime 和 dosdatetime 无法表示零值 time.Time，零值对应全零的线上值
+	sentinels := &struct {
+		NTP  time.Time `struc:"ntp64"`
+		File time.Time `struc:"filetime"`
+		DOS  time.Time `struc:"dosdatetime"`
+	}{}
+	buf.Reset()
+	if err := Pack(&buf, sentinels); err != nil {
+		t.Fatal(err)
+	}
+	if want := make([]byte, 8+8+4); !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("sentinels: got % x, want % x", buf.Bytes(), want)
+	}
+	sentinels.NTP, sentinels.File, sentinels.DOS = time.Unix(1, 0), time.Unix(1, 0), time.Unix(1, 0)
+	if err := Unpack(bytes.NewReader(buf.Bytes()), sentinels); err != nil {
+		t.Fatal(err)
+	}
+	if !sentinels.NTP.IsZero() || !sentinels.File.IsZero() || !sentinels.DOS.IsZero() {
+		t.Fatalf("sentinels round trip: got %+v", sentinels)
+	}
+}
+
+func TestTimeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{"unix32 before epoch", &struct {
+			T time.Time `struc:"unix32"`
+		}{time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)}, "out of range for unix32"},
+		{"unix32 after 2106", &struct {
+			T time.Time `struc:"unix32"`
+		}{time.Date(2107, 1, 1, 0, 0, 0, 0, time.UTC)}, "out of range for unix32"},
+		{"unix32 zero time", &struct {
+			T time.Time `struc:"unix32"`
+		}{}, "out of range for unix32"},
+		{"gpsweek zero time", &struct {
+			T time.Time `struc:"gpsweek"`
+		}{}, "out of range for gpsweek"},
+		{"dos before 1980", &struct {
+			T time.Time `struc:"dosdatetime"`
+		}{time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC)}, "out of range for dosdatetime"},
+		{"negative duration", &struct {
+			D time.Duration `struc:"ntp64"`
+		}{-time.Second}, "out of range for ntp64"},
+		{"wrong go type", &struct {
+			T int64 `struc:"unix32"`
+		}{}, "must be a time.Time or time.Duration"},
+		{"duration as dos", &struct {
+			D time.Duration `struc:"dosdatetime"`
+		}{}, "must be a time.Time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Pack(&bytes.Buffer{}, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -54,21 +54,56 @@ func NewTypeRegistry() *TypeRegistry {
 func (r *TypeRegistry) initBuiltinTypes() {
 	// 注册内置类型名称映射
 	builtinTypes := map[string]Type{
-		"pad":     Pad,
-		"bool":    Bool,
-		"byte":    Uint8,
-		"int8":    Int8,
-		"uint8":   Uint8,
-		"int16":   Int16,
-		"uint16":  Uint16,
-		"int32":   Int32,
-		"uint32":  Uint32,
-		"int64":   Int64,
-		"uint64":  Uint64,
-		"float32": Float32,
-		"float64": Float64,
-		"size_t":  SizeType,
-		"off_t":   OffType,
+		"pad":        Pad,
+		"bool":       Bool,
+		"byte":       Uint8,
+		"int8":       Int8,
+		"uint8":      Uint8,
+		"int16":      Int16,
+		"uint16":     Uint16,
+		"int32":      Int32,
+		"uint32":     Uint32,
+		"int64":      Int64,
+		"uint64":     Uint64,
+		"int24":      Int24,
+		"uint24":     Uint24,
+		"int40":      Int40,
+		"uint40":     Uint40,
+		"int48":      Int48,
+		"uint48":     Uint48,
+		"int56":      Int56,
+		"uint56":     Uint56,
+		"float32":    Float32,
+		"float64":    Float64,
+		"int128":     Int128Type,
+		"uint128":    Uint128Type,
+		"complex64":  Complex64,
+		"complex128": Complex128,
+		"union":      Union,
+		"uvarint":    Uvarint,
+		"varint":     Varint,
+		"sleb128":    Sleb128,
+		"cstring":    CString,
+		"size_t":     SizeType,
+		"off_t":      OffType,
+
+		"unix32":      Unix32,
+		"unix64":      Unix64,
+		"unixms64":    UnixMs64,
+		"ntp64":       NTP64,
+		"filetime":    Filetime,
+		"dosdatetime": DOSDateTime,
+		"gpsweek":     GPSWeek,
+
+		"bcd":      BCD,
+		"octal":    Octal,
+		"decimal":  Decimal,
+		"hexascii": HexASCII,
+
+		"float16":  Float16Type,
+		"bfloat16": BFloat16Type,
+		"fp8e4m3":  FP8E4M3Type,
+		"fp8e5m2":  FP8E5M2Type,
 	}
 
 	for name, typ := range builtinTypes {
@@ -76,6 +111,15 @@ func (r *TypeRegistry) initBuiltinTypes() {
 		r.typeToName[typ] = name
 	}
 
+	// 注册内置类型别名，别名不覆盖规范名称
+	builtinAliases := map[string]Type{
+		"uleb128": Uvarint,
+	}
+
+	for name, typ := range builtinAliases {
+		r.nameToType[name] = typ
+	}
+
 	// 注册内置类型字符串映射
 	additionalTypeNames := map[Type]string{
 		Invalid:    "invalid",
@@ -91,22 +135,24 @@ func (r *TypeRegistry) initBuiltinTypes() {
 
 	// 注册 reflect.Kind 到 Type 的映射
 	builtinKindMappings := map[reflect.Kind]Type{
-		reflect.Bool:    Bool,
-		reflect.Int8:    Int8,
-		reflect.Int16:   Int16,
-		reflect.Int:     Int32,
-		reflect.Int32:   Int32,
-		reflect.Int64:   Int64,
-		reflect.Uint8:   Uint8,
-		reflect.Uint16:  Uint16,
-		reflect.Uint:    Uint32,
-		reflect.Uint32:  Uint32,
-		reflect.Uint64:  Uint64,
-		reflect.Float32: Float32,
-		reflect.Float64: Float64,
-		reflect.String:  String,
-		reflect.Struct:  Struct,
-		reflect.Ptr:     Ptr,
+		reflect.Bool:       Bool,
+		reflect.Int8:       Int8,
+		reflect.Int16:      Int16,
+		reflect.Int:        Int32,
+		reflect.Int32:      Int32,
+		reflect.Int64:      Int64,
+		reflect.Uint8:      Uint8,
+		reflect.Uint16:     Uint16,
+		reflect.Uint:       Uint32,
+		reflect.Uint32:     Uint32,
+		reflect.Uint64:     Uint64,
+		reflect.Float32:    Float32,
+		reflect.Float64:    Float64,
+		reflect.Complex64:  Complex64,
+		reflect.Complex128: Complex128,
+		reflect.String:     String,
+		reflect.Struct:     Struct,
+		reflect.Ptr:        Ptr,
 	}
 
 	for kind, typ := range builtinKindMappings {
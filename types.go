@@ -9,26 +9,59 @@ import (
 type Type int
 
 const (
-	Invalid    Type = iota // 无效类型
-	Pad                    // 填充类型
-	Bool                   // 布尔类型
-	Int                    // 整数类型
-	Int8                   // 8位整数
-	Uint8                  // 8位无符号整数
-	Int16                  // 16位整数
-	Uint16                 // 16位无符号整数
-	Int32                  // 32位整数
-	Uint32                 // 32位无符号整数
-	Int64                  // 64位整数
-	Uint64                 // 64位无符号整数
-	Float32                // 32位浮点数
-	Float64                // 64位浮点数
-	String                 // 字符串类型
-	Struct                 // 结构体类型
-	Ptr                    // 指针类型
-	SizeType               // size_t 类型
-	OffType                // off_t 类型
-	CustomType             // 自定义类型
+	Invalid     Type = iota // 无效类型
+	Pad                     // 填充类型
+	Bool                    // 布尔类型
+	Int                     // 整数类型
+	Int8                    // 8位整数
+	Uint8                   // 8位无符号整数
+	Int16                   // 16位整数
+	Uint16                  // 16位无符号整数
+	Int32                   // 32位整数
+	Uint32                  // 32位无符号整数
+	Int64                   // 64位整数
+	Uint64                  // 64位无符号整数
+	Float32                 // 32位浮点数
+	Float64                 // 64位浮点数
+	String                  // 字符串类型
+	Struct                  // 结构体类型
+	Ptr                     // 指针类型
+	SizeType                // size_t 类型
+	OffType                 // off_t 类型
+	CustomType              // 自定义类型
+	Uvarint                 // 无符号变长整数（ULEB128）
+	Varint                  // 有符号变长整数（ZigZag 编码）
+	Sleb128                 // 有符号变长整数（SLEB128）
+	CString                 // 以 NUL 结尾/填充的 C 字符串
+	Int24                   // 24位整数
+	Uint24                  // 24位无符号整数
+	Int40                   // 40位整数
+	Uint40                  // 40位无符号整数
+	Int48                   // 48位整数
+	Uint48                  // 48位无符号整数
+	Int56                   // 56位整数
+	Uint56                  // 56位无符号整数
+	Int128Type              // 128位整数
+	Uint128Type             // 128位无符号整数
+	Complex64               // 64位复数（两个 float32）
+	Complex128              // 128位复数（两个 float64）
+	Union                   // 由判别字段选择具体类型的联合
+	Unix32                  // 32位 Unix 秒时间戳
+	Unix64                  // 64位 Unix 秒时间戳
+	UnixMs64                // 64位 Unix 毫秒时间戳
+	NTP64                   // 64位 NTP 时间戳
+	Filetime                // Windows FILETIME 时间戳
+	DOSDateTime             // MS-DOS 日期时间
+	GPSWeek                 // GPS 周数和周内秒数
+	BCD                     // 压缩 BCD 整数
+	Octal                   // ASCII 八进制整数
+	Decimal                 // ASCII 十进制整数
+	HexASCII                // ASCII 十六进制整数
+
+	Float16Type  // IEEE 754 半精度浮点数
+	BFloat16Type // bfloat16 浮点数
+	FP8E4M3Type  // FP8 E4M3 浮点数
+	FP8E5M2Type  // FP8 E5M2 浮点数
 )
 
 // Resolve 根据选项解析实际类型
@@ -86,16 +119,32 @@ func (t Type) Size() int {
 	switch t {
 	case SizeType, OffType:
 		panic("Size_t/Off_t types must be converted to another type using options.PtrSize")
-	case Pad, String, Int8, Uint8, Bool:
+	case Uvarint, Varint, Sleb128:
+		panic("variable-length integer types must be sized by value")
+	case CString:
+		panic("cstring types must be sized by field length or value")
+	case Pad, String, Int8, Uint8, Bool, FP8E4M3Type, FP8E5M2Type:
 		return 1
-	case Int16, Uint16:
+	case BCD, Octal, Decimal, HexASCII:
+		return 1 // 按字节计，宽度由字段决定
+	case Int16, Uint16, Float16Type, BFloat16Type:
 		return 2
-	case Int32, Uint32, Float32:
+	case Int24, Uint24:
+		return 3
+	case Int32, Uint32, Float32, Unix32, DOSDateTime:
 		return 4
-	case Int64, Uint64, Float64:
+	case Int40, Uint40:
+		return 5
+	case Int48, Uint48, GPSWeek:
+		return 6
+	case Int56, Uint56:
+		return 7
+	case Int64, Uint64, Float64, Complex64, Unix64, UnixMs64, NTP64, Filetime:
 		return 8
-	case Struct:
-		return 0 // 结构体大小需要通过字段计算
+	case Int128Type, Uint128Type, Complex128:
+		return 16
+	case Struct, Union:
+		return 0 // 结构体和联合的大小需要通过字段计算
 	default:
 		panic("Cannot resolve size of type:" + t.String())
 	}
@@ -114,21 +163,89 @@ func (t Type) IsBasicType() bool {
 	}
 }
 
+// IsVarint 判断是否为变长整数类型
+// 变长整数的编码宽度取决于值本身，不能通过 Size 获取
+func (t Type) IsVarint() bool {
+	switch t {
+	case Uvarint, Varint, Sleb128:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsOddWidthInteger 判断是否为非 2 的幂宽度的整数类型（24/40/48/56 位）
+func (t Type) IsOddWidthInteger() bool {
+	switch t {
+	case Int24, Uint24, Int40, Uint40, Int48, Uint48, Int56, Uint56:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsMinifloat 判断是否为比 float32 更窄的浮点类型（float16、bfloat16 和 FP8）
+func (t Type) IsMinifloat() bool {
+	switch t {
+	case Float16Type, BFloat16Type, FP8E4M3Type, FP8E5M2Type:
+		return true
+	default:
+		return false
+	}
+}
+
 // typeStrToType 定义了字符串到类型的映射关系
 var typeStrToType = map[string]Type{
-	"pad":     Pad,
-	"bool":    Bool,
-	"byte":    Uint8,
-	"int8":    Int8,
-	"uint8":   Uint8,
-	"int16":   Int16,
-	"uint16":  Uint16,
-	"int32":   Int32,
-	"uint32":  Uint32,
-	"int64":   Int64,
-	"uint64":  Uint64,
-	"float32": Float32,
-	"float64": Float64,
+	"pad":        Pad,
+	"bool":       Bool,
+	"byte":       Uint8,
+	"int8":       Int8,
+	"uint8":      Uint8,
+	"int16":      Int16,
+	"uint16":     Uint16,
+	"int32":      Int32,
+	"uint32":     Uint32,
+	"int64":      Int64,
+	"uint64":     Uint64,
+	"int24":      Int24,
+	"uint24":     Uint24,
+	"int40":      Int40,
+	"uint40":     Uint40,
+	"int48":      Int48,
+	"uint48":     Uint48,
+	"int56":      Int56,
+	"uint56":     Uint56,
+	"int128":     Int128Type,
+	"uint128":    Uint128Type,
+	"float32":    Float32,
+	"float64":    Float64,
+	"complex64":  Complex64,
+	"complex128": Complex128,
+	"union":      Union,
+
+	"float16":  Float16Type,
+	"bfloat16": BFloat16Type,
+	"fp8e4m3":  FP8E4M3Type,
+	"fp8e5m2":  FP8E5M2Type,
+
+	"unix32":      Unix32,
+	"unix64":      Unix64,
+	"unixms64":    UnixMs64,
+	"ntp64":       NTP64,
+	"filetime":    Filetime,
+	"dosdatetime": DOSDateTime,
+	"gpsweek":     GPSWeek,
+
+	"bcd":      BCD,
+	"octal":    Octal,
+	"decimal":  Decimal,
+	"hexascii": HexASCII,
+
+	"uvarint": Uvarint,
+	"uleb128": Uvarint,
+	"varint":  Varint,
+	"sleb128": Sleb128,
+	"cstring": CString,
 
 	"size_t": SizeType,
 	"off_t":  OffType,
@@ -136,31 +253,67 @@ var typeStrToType = map[string]Type{
 
 // typeToString 定义了类型到字符串的映射关系
 var typeToString = map[Type]string{
-	Invalid:    "invalid",
-	Pad:        "pad",
-	Bool:       "bool",
-	Int8:       "int8",
-	Int16:      "int16",
-	Int32:      "int32",
-	Int64:      "int64",
-	Uint8:      "uint8",
-	Uint16:     "uint16",
-	Uint32:     "uint32",
-	Uint64:     "uint64",
-	Float32:    "float32",
-	Float64:    "float64",
-	String:     "string",
-	Struct:     "struct",
-	Ptr:        "ptr",
-	SizeType:   "size_t",
-	OffType:    "off_t",
-	CustomType: "custom",
+	Invalid:     "invalid",
+	Pad:         "pad",
+	Bool:        "bool",
+	Int8:        "int8",
+	Int16:       "int16",
+	Int32:       "int32",
+	Int64:       "int64",
+	Uint8:       "uint8",
+	Uint16:      "uint16",
+	Uint32:      "uint32",
+	Uint64:      "uint64",
+	Float32:     "float32",
+	Float64:     "float64",
+	String:      "string",
+	Struct:      "struct",
+	Ptr:         "ptr",
+	SizeType:    "size_t",
+	OffType:     "off_t",
+	CustomType:  "custom",
+	Uvarint:     "uvarint",
+	Varint:      "varint",
+	Sleb128:     "sleb128",
+	CString:     "cstring",
+	Int24:       "int24",
+	Uint24:      "uint24",
+	Int40:       "int40",
+	Uint40:      "uint40",
+	Int48:       "int48",
+	Uint48:      "uint48",
+	Int56:       "int56",
+	Uint56:      "uint56",
+	Int128Type:  "int128",
+	Uint128Type: "uint128",
+	Complex64:   "complex64",
+	Complex128:  "complex128",
+	Union:       "union",
+	Unix32:      "unix32",
+	Unix64:      "unix64",
+	UnixMs64:    "unixms64",
+	NTP64:       "ntp64",
+	Filetime:    "filetime",
+	DOSDateTime: "dosdatetime",
+	GPSWeek:     "gpsweek",
+	BCD:         "bcd",
+	Octal:       "octal",
+	Decimal:     "decimal",
+	HexASCII:    "hexascii",
+
+	Float16Type:  "float16",
+	BFloat16Type: "bfloat16",
+	FP8E4M3Type:  "fp8e4m3",
+	FP8E5M2Type:  "fp8e5m2",
 }
 
 // init 初始化类型到字符串的映射
+// 已有的规范名称不会被别名（如 byte、uleb128）覆盖
 func init() {
 	for name, enum := range typeStrToType {
-		typeToString[enum] = name
+		if _, exists := typeToString[enum]; !exists {
+			typeToString[enum] = name
+		}
 	}
 }
 
@@ -173,20 +326,22 @@ type Off_t int64
 // typeKindToType 定义了 reflect.Kind 到 Type 的映射关系
 // 用于将 Go 的反射类型转换为 struc 包的类型系统
 var typeKindToType = map[reflect.Kind]Type{
-	reflect.Bool:    Bool,
-	reflect.Int8:    Int8,
-	reflect.Int16:   Int16,
-	reflect.Int:     Int32,
-	reflect.Int32:   Int32,
-	reflect.Int64:   Int64,
-	reflect.Uint8:   Uint8,
-	reflect.Uint16:  Uint16,
-	reflect.Uint:    Uint32,
-	reflect.Uint32:  Uint32,
-	reflect.Uint64:  Uint64,
-	reflect.Float32: Float32,
-	reflect.Float64: Float64,
-	reflect.String:  String,
-	reflect.Struct:  Struct,
-	reflect.Ptr:     Ptr,
+	reflect.Bool:       Bool,
+	reflect.Int8:       Int8,
+	reflect.Int16:      Int16,
+	reflect.Int:        Int32,
+	reflect.Int32:      Int32,
+	reflect.Int64:      Int64,
+	reflect.Uint8:      Uint8,
+	reflect.Uint16:     Uint16,
+	reflect.Uint:       Uint32,
+	reflect.Uint32:     Uint32,
+	reflect.Uint64:     Uint64,
+	reflect.Float32:    Float32,
+	reflect.Float64:    Float64,
+	reflect.Complex64:  Complex64,
+	reflect.Complex128: Complex128,
+	reflect.String:     String,
+	reflect.Struct:     Struct,
+	reflect.Ptr:        Ptr,
 }
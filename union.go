@@ -0,0 +1,303 @@
+package struc
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// RawUnion 保存未注册判别值对应的原始字节
+// 当联合字段带有 sizefrom 且接口类型可以容纳 RawUnion 时，
+// 未知判别值的内容会以 RawUnion 的形式解包，打包时原样写回
+type RawUnion []byte
+
+var rawUnionType = reflect.TypeOf(RawUnion(nil))
+
+// unionVariants 记录一个接口类型的所有变体
+type unionVariants struct {
+	byKey map[uint64]reflect.Type // 判别值 -> 具体类型
+}
+
+// unionRegistry 保存接口类型到变体集合的映射 (并发安全)
+var unionRegistry = struct {
+	sync.RWMutex
+	variants map[reflect.Type]*unionVariants
+}{variants: make(map[reflect.Type]*unionVariants)}
+
+// RegisterUnion 为接口类型注册判别值对应的具体结构体类型
+// iface 为指向接口的 nil 指针，如 (*Body)(nil)；variant 为结构体或结构体指针的示例值，
+// 必须实现该接口。解包时按判别值创建与 variant 相同类型的值
+//
+// 示例：
+//
+//	struc.RegisterUnion((*Body)(nil), 1, &Ping{})
+//	struc.RegisterUnion((*Body)(nil), 2, &Pong{})
+func RegisterUnion(iface interface{}, discriminator uint64, variant interface{}) error {
+	ifaceType := reflect.TypeOf(iface)
+	if ifaceType == nil || ifaceType.Kind() != reflect.Ptr || ifaceType.Elem().Kind() != reflect.Interface {
+		return ErrTypeRegistrationf("union interface must be a nil pointer to an interface, got %T", iface)
+	}
+	ifaceType = ifaceType.Elem()
+
+	variantType := reflect.TypeOf(variant)
+	if variantType == nil {
+		return ErrTypeRegistrationf("union variant for discriminator %d is nil", discriminator)
+	}
+	structType := variantType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return ErrTypeRegistrationf("union variant %v is not a struct", variantType)
+	}
+	if !variantType.Implements(ifaceType) {
+		return ErrTypeRegistrationf("union variant %v does not implement %v", variantType, ifaceType)
+	}
+	// 注册时解析变体，标签错误在此报告，而不是在 Sizeof 中被当作 0 字节
+	if _, err := parseFields(reflect.New(structType).Elem()); err != nil {
+		return ErrTypeRegistrationf("union variant %v: %v", variantType, err)
+	}
+
+	unionRegistry.Lock()
+	defer unionRegistry.Unlock()
+
+	variants, ok := unionRegistry.variants[ifaceType]
+	if !ok {
+		variants = &unionVariants{byKey: make(map[uint64]reflect.Type)}
+		unionRegistry.variants[ifaceType] = variants
+	}
+	if existing, ok := variants.byKey[discriminator]; ok && existing != variantType {
+		return ErrTypeRegistrationf("union discriminator %d of %v already registered to %v", discriminator, ifaceType, existing)
+	}
+	variants.byKey[discriminator] = variantType
+	return nil
+}
+
+// lookupUnionVariant 查找判别值对应的具体类型
+func lookupUnionVariant(ifaceType reflect.Type, discriminator uint64) (reflect.Type, bool) {
+	unionRegistry.RLock()
+	defer unionRegistry.RUnlock()
+
+	variants, ok := unionRegistry.variants[ifaceType]
+	if !ok {
+		return nil, false
+	}
+	variantType, ok := variants.byKey[discriminator]
+	return variantType, ok
+}
+
+// unionDiscriminators 返回具体类型注册过的所有判别值
+func unionDiscriminators(ifaceType, variantType reflect.Type) []uint64 {
+	unionRegistry.RLock()
+	defer unionRegistry.RUnlock()
+
+	variants, ok := unionRegistry.variants[ifaceType]
+	if !ok {
+		return nil
+	}
+	var keys []uint64
+	for key, typ := range variants.byKey {
+		if typ == variantType {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// handleUnionTag 处理联合字段的 switch 标签
+// 判别字段必须是位于联合字段之前的整数字段，并反向记录所属的联合字段
+func handleUnionTag(fieldDesc *Field, fieldTag *strucTag, structType reflect.Type, field reflect.StructField, fields Fields) error {
+	if fieldDesc.Type != Union {
+		if fieldTag.Switch != "" {
+			return fmt.Errorf("struc: `switch=%s` requires a union field (field `%s`)", fieldTag.Switch, field.Name)
+		}
+		return nil
+	}
+	if field.Type.Kind() != reflect.Interface {
+		return fmt.Errorf("struc: union field `%s` must be an interface, got %v", field.Name, field.Type)
+	}
+	if fieldTag.Switch == "" {
+		return fmt.Errorf("struc: union field `%s` requires a `switch=` discriminator", field.Name)
+	}
+	if fieldDesc.Sizeof != nil {
+		return fmt.Errorf("struc: union field `%s` cannot be a sizeof field", field.Name)
+	}
+
+	switchField, ok := structType.FieldByName(fieldTag.Switch)
+	if !ok {
+		return fmt.Errorf("struc: `switch=%s` field does not exist", fieldTag.Switch)
+	}
+	if switchField.Index[0] >= field.Index[0] {
+		return fmt.Errorf("struc: `switch=%s` must precede union field `%s`", fieldTag.Switch, field.Name)
+	}
+	switch switchField.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return fmt.Errorf("struc: `switch=%s` must be an integer field", fieldTag.Switch)
+	}
+
+	discriminator := fields[switchField.Index[0]]
+	if discriminator == nil {
+		return fmt.Errorf("struc: `switch=%s` field is skipped", fieldTag.Switch)
+	}
+	discriminator.switchFor = field.Index
+	fieldDesc.Switch = switchField.Index
+	return nil
+}
+
+// discriminatorValue 读取判别字段的值，有符号数按补码转换
+func discriminatorValue(value reflect.Value) uint64 {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(value.Int())
+	default:
+		return value.Uint()
+	}
+}
+
+// setDiscriminator 在打包判别字段前根据联合字段的具体类型确定判别值
+// 当前值已对应该类型时保持不变；否则仅在该类型只注册了一个判别值时自动填充
+func (f Fields) setDiscriminator(structValue reflect.Value, field *Field, fieldValue reflect.Value) error {
+	unionValue := structValue.FieldByIndex(field.switchFor)
+	if unionValue.IsNil() {
+		return nil
+	}
+	variantType := unionValue.Elem().Type()
+	if variantType == rawUnionType {
+		return nil
+	}
+
+	ifaceType := unionValue.Type()
+	if current, ok := lookupUnionVariant(ifaceType, discriminatorValue(fieldValue)); ok && current == variantType {
+		return nil
+	}
+
+	keys := unionDiscriminators(ifaceType, variantType)
+	switch len(keys) {
+	case 0:
+		return fmt.Errorf("struc: %v is not a registered variant of %v (field `%s`)", variantType, ifaceType, field.Name)
+	case 1:
+		switch fieldValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fieldValue.SetInt(int64(keys[0]))
+		default:
+			fieldValue.SetUint(keys[0])
+		}
+		return nil
+	default:
+		return fmt.Errorf("struc: %v is registered for several discriminators of %v, set `%s` explicitly", variantType, ifaceType, field.Name)
+	}
+}
+
+// unionVariantValue 返回联合字段中保存的可寻址具体值，指针会被解引用
+// 接口为 nil 或保存 nil 指针时返回 false
+func unionVariantValue(fieldValue reflect.Value) (reflect.Value, bool) {
+	if fieldValue.IsNil() {
+		return reflect.Value{}, false
+	}
+	variant := fieldValue.Elem()
+	if variant.Kind() == reflect.Ptr {
+		if variant.IsNil() {
+			return reflect.Value{}, false
+		}
+		return variant.Elem(), true
+	}
+	// 以值方式保存的结构体不可寻址，复制一份以便写入 sizeof 字段和调用自定义类型方法
+	addressable := reflect.New(variant.Type()).Elem()
+	addressable.Set(variant)
+	return addressable, true
+}
+
+// unionSize 计算联合字段当前值的字节大小，nil 视为 0
+func unionSize(fieldValue reflect.Value, options *Options) int {
+	variant, ok := unionVariantValue(fieldValue)
+	if !ok {
+		return 0
+	}
+	if variant.Type() == rawUnionType {
+		return variant.Len()
+	}
+	// 注册的变体已在 RegisterUnion 中解析过，这里只会遇到未注册的类型
+	fields, err := parseFields(variant)
+	if err != nil {
+		return 0
+	}
+	return fields.Sizeof(variant, options)
+}
+
+// packUnion 打包联合字段的具体值
+func (f *Field) packUnion(buffer []byte, fieldValue reflect.Value, options *Options) (int, error) {
+	variant, ok := unionVariantValue(fieldValue)
+	if !ok {
+		return 0, fmt.Errorf("struc: union field `%s` is nil", f.Name)
+	}
+	if variant.Type() == rawUnionType {
+		return copy(buffer, variant.Bytes()), nil
+	}
+	fields, err := parseFields(variant)
+	if err != nil {
+		return 0, err
+	}
+	return fields.Pack(buffer, variant, options)
+}
+
+// unpackUnion 根据已解包的判别字段选择具体类型并解包
+// 带 sizefrom 时联合内容被限制在声明的字节数内，未读完的部分会被跳过
+func (f Fields) unpackUnion(reader io.Reader, structValue, fieldValue reflect.Value, field *Field, options *Options, scratch *scratchArena) error {
+	discriminator := discriminatorValue(structValue.FieldByIndex(field.Switch))
+	byteLength := -1
+	if field.Sizefrom != nil {
+		length, err := f.lengthFrom(structValue, field, options)
+		if err != nil {
+			return err
+		}
+		byteLength = length
+	}
+
+	variantType, ok := lookupUnionVariant(fieldValue.Type(), discriminator)
+	if !ok {
+		if byteLength < 0 || !rawUnionType.AssignableTo(fieldValue.Type()) {
+			return NewError(ErrUnknownDiscriminator,
+				fmt.Sprintf("unknown discriminator %d for union field `%s`", discriminator, field.Name),
+				map[string]interface{}{"field": field.Name, "discriminator": discriminator})
+		}
+		raw := make([]byte, byteLength)
+		if _, err := io.ReadFull(reader, raw); err != nil {
+			return err
+		}
+		fieldValue.Set(reflect.ValueOf(RawUnion(raw)))
+		return nil
+	}
+
+	isPointer := variantType.Kind() == reflect.Ptr
+	if isPointer {
+		variantType = variantType.Elem()
+	}
+	variant := reflect.New(variantType)
+
+	fields, err := parseFields(variant.Elem())
+	if err != nil {
+		return err
+	}
+
+	if byteLength < 0 {
+		err = fields.unpackWithScratch(reader, variant, options, scratch)
+	} else {
+		limited := &io.LimitedReader{R: reader, N: int64(byteLength)}
+		if err = fields.unpackWithScratch(limited, variant, options, scratch); err == nil && limited.N > 0 {
+			_, err = io.CopyN(io.Discard, limited, limited.N)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if isPointer {
+		fieldValue.Set(variant)
+	} else {
+		fieldValue.Set(variant.Elem())
+	}
+	return nil
+}
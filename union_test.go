@@ -0,0 +1,150 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type unionBody interface {
+	unionKind() string
+}
+
+type unionPing struct {
+	Seq uint16
+}
+
+func (*unionPing) unionKind() string { return "ping" }
+
+type unionData struct {
+	Len  int `struc:"uint8,sizeof=Data"`
+	Data []byte
+}
+
+func (*unionData) unionKind() string { return "data" }
+
+func (RawUnion) unionKind() string { return "raw" }
+
+type unionBroken struct {
+	Len int `struc:"uint8,sizeof=Missing"`
+}
+
+func (*unionBroken) unionKind() string { return "broken" }
+
+type unionMessage struct {
+	Kind uint8
+	Body unionBody `struc:"union,switch=Kind"`
+	Tail uint8
+}
+
+type unionFramed struct {
+	Kind    uint8
+	BodyLen uint16    `struc:"uint16,sizeof=Body"`
+	Body    unionBody `struc:"union,switch=Kind,sizefrom=BodyLen"`
+}
+
+func init() {
+	for key, variant := range map[uint64]interface{}{1: &unionPing{}, 2: &unionData{}} {
+		if err := RegisterUnion((*unionBody)(nil), key, variant); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func TestUnionPackUnpack(t *testing.T) {
+	in := &unionMessage{Body: &unionData{Data: []byte("hi")}, Tail: 9}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{2, 2, 'h', 'i', 9}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("union pack: got %x, want %x", buf.Bytes(), want)
+	}
+	if in.Kind != 2 {
+		t.Fatalf("discriminator not filled in: %d", in.Kind)
+	}
+	if size, err := Sizeof(in); err != nil || size != len(want) {
+		t.Fatalf("union sizeof: got %d (%v), want %d", size, err, len(want))
+	}
+
+	out := &unionMessage{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("union round trip: got %+v, want %+v", out, in)
+	}
+
+	if err := Unpack(bytes.NewReader([]byte{7, 0, 0}), &unionMessage{}); !IsUnknownDiscriminator(err) {
+		t.Fatalf("expected unknown discriminator error, got %v", err)
+	}
+	// 顶层切片会包装元素的错误
+	if err := Unpack(bytes.NewReader([]byte{9, 0, 0}), &[]unionMessage{{}}); !IsUnknownDiscriminator(err) {
+		t.Fatalf("expected wrapped unknown discriminator error, got %v", err)
+	}
+}
+
+func TestUnionSizefromAndRawFallback(t *testing.T) {
+	in := &unionFramed{Body: &unionPing{Seq: 0x0102}}
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{1, 0, 2, 1, 2}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("framed union pack: got %x, want %x", buf.Bytes(), want)
+	}
+
+	// 声明的长度大于变体实际大小时，多余字节被跳过
+	padded := &unionFramed{}
+	if err := Unpack(bytes.NewReader([]byte{1, 0, 4, 1, 2, 0xee, 0xee}), padded); err != nil {
+		t.Fatal(err)
+	}
+	if ping, ok := padded.Body.(*unionPing); !ok || ping.Seq != 0x0102 {
+		t.Fatalf("framed union unpack: got %+v", padded.Body)
+	}
+
+	raw := []byte{9, 0, 3, 0xaa, 0xbb, 0xcc}
+	out := &unionFramed{}
+	if err := Unpack(bytes.NewReader(raw), out); err != nil {
+		t.Fatal(err)
+	}
+	if body, ok := out.Body.(RawUnion); !ok || !bytes.Equal(body, raw[3:]) {
+		t.Fatalf("raw fallback: got %#v", out.Body)
+	}
+	buf.Reset()
+	if err := Pack(&buf, out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), raw) {
+		t.Fatalf("raw fallback repack: got %x, want %x", buf.Bytes(), raw)
+	}
+}
+
+func TestUnionTagErrors(t *testing.T) {
+	if _, err := Sizeof(&struct {
+		Body unionBody `struc:"union"`
+	}{}); err == nil {
+		t.Fatal("expected error for union without switch")
+	}
+	if _, err := Sizeof(&struct {
+		Body unionBody `struc:"union,switch=Kind"`
+		Kind uint8
+	}{}); err == nil {
+		t.Fatal("expected error for discriminator after union")
+	}
+	if err := RegisterUnion((*unionBody)(nil), 1, &unionData{}); err == nil {
+		t.Fatal("expected error for duplicate discriminator")
+	}
+	if err := RegisterUnion((*unionBody)(nil), 3, unionPing{}); err == nil {
+		t.Fatal("expected error for variant not implementing interface")
+	}
+	if err := RegisterUnion((*unionBody)(nil), 4, &unionBroken{}); err == nil || !strings.Contains(err.Error(), "sizeof=Missing") {
+		t.Fatalf("expected registration error for variant with invalid tags, got %v", err)
+	}
+	if _, ok := lookupUnionVariant(reflect.TypeOf((*unionBody)(nil)).Elem(), 4); ok {
+		t.Fatal("invalid variant must not be registered")
+	}
+}
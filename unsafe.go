@@ -43,8 +43,15 @@ func unsafeString2Bytes(s string) []byte {
 }
 
 // unsafeSetSlice 使用 unsafe 直接设置切片的底层数据, 避免内存拷贝
+// 长度为 0 时设置为 nil 切片，避免对空缓冲区取址
 func unsafeSetSlice(fieldValue reflect.Value, buffer []byte, length int) {
 	sh := (*unsafeSliceHeader)(unsafe.Pointer(fieldValue.UnsafeAddr()))
+	if length == 0 {
+		sh.Data = 0
+		sh.Len = 0
+		sh.Cap = 0
+		return
+	}
 	sh.Data = uintptr(unsafe.Pointer(&buffer[0]))
 	sh.Len = length
 	sh.Cap = length
@@ -0,0 +1,213 @@
+package struc
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// 哨兵终止切片
+//
+// until= 标签用于没有长度字段、以终止元素结尾的列表：
+//
+//   - until=0xff: 元素等于该数值时结束（适用于整数、浮点数和布尔元素）
+//   - until=zero: 元素为零值时结束（如全零的结构体记录）
+//   - until=name: 使用 RegisterSentinel 注册的判断函数
+//
+// 解包时逐个读取元素直到遇到终止元素（终止元素不加入切片），打包时在元素之后自动写入终止元素。
+// string 字段按字节处理，例如 until=0x0a 读取到换行符为止。
+
+// SentinelFunc 判断解包得到的元素是否为终止元素
+type SentinelFunc func(element interface{}) bool
+
+// sentinel 描述已注册的终止条件
+type sentinel struct {
+	match      SentinelFunc
+	terminator interface{}
+}
+
+// sentinelRegistry 保存通过名称注册的终止条件 (并发安全)
+var sentinelRegistry = struct {
+	sync.RWMutex
+	sentinels map[string]sentinel
+}{sentinels: make(map[string]sentinel)}
+
+// RegisterSentinel 注册可在 until= 标签中使用的终止条件
+// terminator 为打包时写入的终止元素，类型需与切片元素一致；为 nil 时写入元素的零值
+func RegisterSentinel(name string, match SentinelFunc, terminator interface{}) error {
+	if name == "" || match == nil {
+		return ErrTypeRegistrationf("sentinel name and match function must not be empty")
+	}
+	if name == "zero" {
+		return ErrTypeRegistrationf("sentinel name '%s' is reserved", name)
+	}
+	sentinelRegistry.Lock()
+	defer sentinelRegistry.Unlock()
+
+	if _, exists := sentinelRegistry.sentinels[name]; exists {
+		return ErrTypeRegistrationf("sentinel '%s' already registered", name)
+	}
+	sentinelRegistry.sentinels[name] = sentinel{match: match, terminator: terminator}
+	return nil
+}
+
+// lookupSentinel 查找已注册的终止条件
+func lookupSentinel(name string) (sentinel, bool) {
+	sentinelRegistry.RLock()
+	defer sentinelRegistry.RUnlock()
+	s, ok := sentinelRegistry.sentinels[name]
+	return s, ok
+}
+
+// untilSpec 描述切片的终止元素
+type untilSpec struct {
+	terminator reflect.Value            // 打包时写入的终止元素
+	match      func(reflect.Value) bool // 判断解包得到的元素是否为终止元素
+}
+
+// handleUntilTag 解析 until= 标签
+func handleUntilTag(fieldDesc *Field, fieldTag *strucTag, field reflect.StructField) error {
+	if fieldTag.Until == "" {
+		return nil
+	}
+
+	var elementType reflect.Type
+	switch {
+	case field.Type.Kind() == reflect.String:
+		elementType = reflect.TypeOf(byte(0))
+	case fieldDesc.IsSlice && !fieldDesc.IsArray && field.Type.Elem().Kind() != reflect.String:
+		elementType = field.Type.Elem()
+	default:
+		return fmt.Errorf("struc: until field `%s` must be a slice or string", field.Name)
+	}
+	if fieldDesc.Sizefrom != nil || fieldDesc.Sizeof != nil || fieldDesc.Prefix != Invalid || fieldDesc.Rest ||
+		fieldDesc.IsPointer || fieldDesc.constValue.IsValid() {
+		return fmt.Errorf("struc: until field `%s` cannot have an explicit length", field.Name)
+	}
+	switch fieldDesc.Type {
+	case CustomType, CString, Union, Pad, Uvarint, Varint, Sleb128, Int128Type, Uint128Type, Complex64, Complex128:
+		return fmt.Errorf("struc: until is not supported for %s field `%s`", fieldDesc.Type, field.Name)
+	}
+
+	spec := &untilSpec{}
+	if fieldTag.Until == "zero" {
+		spec.terminator = reflect.Zero(elementType)
+		spec.match = func(element reflect.Value) bool { return element.IsZero() }
+	} else if registered, ok := lookupSentinel(fieldTag.Until); ok {
+		spec.terminator = reflect.Zero(elementType)
+		if registered.terminator != nil {
+			terminator := reflect.ValueOf(registered.terminator)
+			if !terminator.Type().ConvertibleTo(elementType) {
+				return fmt.Errorf("struc: sentinel `%s` terminator %v does not match element type %v of field `%s`",
+					fieldTag.Until, terminator.Type(), elementType, field.Name)
+			}
+			spec.terminator = terminator.Convert(elementType)
+		}
+		spec.match = func(element reflect.Value) bool { return registered.match(element.Interface()) }
+	} else {
+		terminator := reflect.New(elementType).Elem()
+		if err := parseConstNumber(terminator, fieldTag.Until); err != nil {
+			return fmt.Errorf("struc: invalid `until=%s` (field `%s`): %v", fieldTag.Until, field.Name, err)
+		}
+		spec.terminator = terminator
+		spec.match = func(element reflect.Value) bool { return element.Interface() == terminator.Interface() }
+	}
+
+	fieldDesc.until = spec
+	return nil
+}
+
+// untilSize 返回终止元素占用的字节数
+func (f *Field) untilSize(options *Options) int {
+	switch {
+	case f.kind == reflect.String:
+		return 1
+	case f.Type == Struct:
+		return f.NestFields.Sizeof(f.until.terminator, options)
+	default:
+		return resolveTypeForOptions(f.Type, options).Size()
+	}
+}
+
+// packUntil 写入切片元素，并在之后写入终止元素
+// 内容中出现终止元素时无法正确解包，因此报错
+func (f *Field) packUntil(buffer []byte, fieldValue reflect.Value, options *Options) (int, error) {
+	if f.kind == reflect.String {
+		if index := strings.IndexByte(fieldValue.String(), byte(f.until.terminator.Uint())); index >= 0 {
+			return 0, fmt.Errorf("struc: until field `%s` contains the terminator at byte %d", f.Name, index)
+		}
+		n := copy(buffer, fieldValue.String())
+		buffer[n] = byte(f.until.terminator.Uint())
+		return n + 1, nil
+	}
+
+	for i := 0; i < fieldValue.Len(); i++ {
+		if f.until.match(fieldValue.Index(i)) {
+			return 0, fmt.Errorf("struc: until field `%s` contains the terminator at element %d", f.Name, i)
+		}
+	}
+	position, err := f.packSliceValue(buffer, fieldValue, fieldValue.Len(), options)
+	if err != nil {
+		return position, err
+	}
+	terminator := reflect.New(f.until.terminator.Type()).Elem()
+	terminator.Set(f.until.terminator)
+	n, err := f.packSingleValue(buffer[position:], terminator, 1, options)
+	return position + n, err
+}
+
+// unpackUntil 逐个读取元素，直到遇到终止元素
+func (f Fields) unpackUntil(reader io.Reader, fieldValue reflect.Value, field *Field, options *Options, scratch *scratchArena) error {
+	elementType := field.until.terminator.Type()
+	var values reflect.Value
+	if field.kind == reflect.String {
+		values = reflect.MakeSlice(reflect.TypeOf([]byte(nil)), 0, 16)
+	} else {
+		values = reflect.MakeSlice(fieldValue.Type(), 0, 0)
+	}
+
+	nested := field.NestFields
+	if field.Type == Struct && nested == nil {
+		var err error
+		if nested, err = parseFields(reflect.New(elementType).Elem()); err != nil {
+			return err
+		}
+	}
+
+	for {
+		element := reflect.New(elementType).Elem()
+		if field.Type == Struct {
+			if err := nested.unpackWithScratch(reader, element, options, scratch); err != nil {
+				return err
+			}
+		} else {
+			elementSize := 1
+			if field.kind != reflect.String {
+				elementSize = resolveTypeForOptions(field.Type, options).Size()
+			}
+			buffer := scratch.Get(elementSize)
+			if _, err := io.ReadFull(reader, buffer); err != nil {
+				return err
+			}
+			if field.kind == reflect.String {
+				element.SetUint(uint64(buffer[0]))
+			} else if err := field.unpackSingleValue(buffer, element, 1, options); err != nil {
+				return err
+			}
+		}
+
+		if field.until.match(element) {
+			break
+		}
+		values = reflect.Append(values, element)
+	}
+
+	if field.kind == reflect.String {
+		fieldValue.SetString(string(values.Bytes()))
+	} else {
+		fieldValue.Set(values)
+	}
+	return nil
+}
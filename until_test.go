@@ -0,0 +1,155 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type untilEntry struct {
+	Name string `struc:"cstring"`
+	Mode uint8
+}
+
+type untilDirectory struct {
+	Count   uint8
+	Codes   []byte       `struc:"[]byte,until=0xff"`
+	Values  []int16      `struc:"[]int16,until=-1"`
+	Line    string       `struc:"until=0x0a"`
+	Entries []untilEntry `struc:"until=zero"`
+	Tail    uint8
+}
+
+func TestUntilFields(t *testing.T) {
+	in := &untilDirectory{
+		Count:   3,
+		Codes:   []byte{1, 2},
+		Values:  []int16{0x0102},
+		Line:    "hi",
+		Entries: []untilEntry{{Name: "a", Mode: 1}, {Name: "bc", Mode: 2}},
+		Tail:    9,
+	}
+	want := []byte{
+		3,
+		1, 2, 0xff,
+		1, 2, 0xff, 0xff,
+		'h', 'i', '\n',
+		'a', 0, 1, 'b', 'c', 0, 2, 0, 0,
+		9,
+	}
+
+	var buf bytes.Buffer
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("pack: got %x, want %x", buf.Bytes(), want)
+	}
+	if size, err := Sizeof(in); err != nil || size != len(want) {
+		t.Fatalf("sizeof: got %d (%v), want %d", size, err, len(want))
+	}
+
+	out := &untilDirectory{}
+	if err := Unpack(bytes.NewReader(want), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip: got %+v, want %+v", out, in)
+	}
+}
+
+func TestUntilRegisteredSentinel(t *testing.T) {
+	// 以大于等于 0x80 的字节结束，打包时写入 0x80
+	err := RegisterSentinel("until-test-high", func(element interface{}) bool {
+		return element.(uint8) >= 0x80
+	}, uint8(0x80))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterSentinel("zero", func(interface{}) bool { return false }, nil); err == nil {
+		t.Fatal("expected reserved name error")
+	}
+
+	type frame struct {
+		Data []uint8 `struc:"[]uint8,until=until-test-high"`
+	}
+	var buf bytes.Buffer
+	if err := Pack(&buf, &frame{Data: []uint8{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{1, 2, 0x80}) {
+		t.Fatalf("pack: got %x", buf.Bytes())
+	}
+
+	out := &frame{}
+	if err := Unpack(bytes.NewReader([]byte{5, 0xfe, 7}), out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Data, []byte{5}) {
+		t.Fatalf("unpack: got %x", out.Data)
+	}
+}
+
+func TestUntilFieldErrors(t *testing.T) {
+	err := Unpack(bytes.NewReader([]byte{1, 2}), &struct {
+		Data []byte `struc:"[]byte,until=0"`
+	}{})
+	if err == nil {
+		t.Fatal("expected error for missing terminator")
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"array", &struct {
+			Data [4]byte `struc:"until=0"`
+		}{}, "must be a slice or string"},
+		{"bad value", &struct {
+			Data []byte `struc:"[]byte,until=0x100"`
+		}{}, "invalid `until=0x100`"},
+		{"struct value", &struct {
+			Entries []untilEntry `struc:"until=1"`
+		}{}, "invalid `until=1`"},
+		{"with sizefrom", &struct {
+			N    uint8
+			Data []byte `struc:"sizefrom=N,until=0"`
+		}{}, "explicit length"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Sizeof(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestUntilTerminatorInPayload(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"byte slice", &struct {
+			L []uint8 `struc:"until=0xff"`
+		}{[]uint8{1, 0xff, 3}}, "terminator at element 1"},
+		{"string", &struct {
+			S string `struc:"until=0x0a"`
+		}{"a\nb"}, "terminator at byte 1"},
+		{"zero struct", &struct {
+			Entries []untilEntry `struc:"until=zero"`
+		}{[]untilEntry{{Name: "a", Mode: 1}, {}}}, "terminator at element 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Pack(&bytes.Buffer{}, tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package struc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// 变长整数支持
+//
+// - uvarint / uleb128: 无符号 LEB128 编码（protobuf varint、DWARF ULEB128、WebAssembly）
+// - varint: ZigZag 编码的有符号变长整数（protobuf sint、encoding/binary.PutVarint）
+// - sleb128: 有符号 LEB128 编码（DWARF SLEB128、WebAssembly）
+//
+// 变长整数的编码宽度取决于值本身，可以作为普通字段、切片元素或 sizeof 长度字段使用。
+
+// maxVarintLen 是 64 位整数变长编码的最大字节数
+const maxVarintLen = 10
+
+// errVarintOverflow 表示变长整数超出 64 位范围
+var errVarintOverflow = errors.New("struc: varint overflows a 64-bit integer")
+
+// varintSize 返回整数值按指定变长类型编码后的字节数
+func varintSize(t Type, value uint64) int {
+	switch t {
+	case Varint:
+		signed := int64(value)
+		value = uint64(signed<<1) ^ uint64(signed>>63)
+	case Sleb128:
+		signed := int64(value)
+		size := 1
+		for {
+			b := signed & 0x7f
+			signed >>= 7
+			if (signed == 0 && b&0x40 == 0) || (signed == -1 && b&0x40 != 0) {
+				return size
+			}
+			size++
+		}
+	}
+	size := 1
+	for value >= 0x80 {
+		value >>= 7
+		size++
+	}
+	return size
+}
+
+// putVarint 将整数值按指定变长类型编码到缓冲区，返回写入的字节数
+func putVarint(buffer []byte, t Type, value uint64) int {
+	switch t {
+	case Varint:
+		signed := int64(value)
+		value = uint64(signed<<1) ^ uint64(signed>>63)
+	case Sleb128:
+		signed := int64(value)
+		i := 0
+		for {
+			b := byte(signed & 0x7f)
+			signed >>= 7
+			if (signed == 0 && b&0x40 == 0) || (signed == -1 && b&0x40 != 0) {
+				buffer[i] = b
+				return i + 1
+			}
+			buffer[i] = b | 0x80
+			i++
+		}
+	}
+	i := 0
+	for value >= 0x80 {
+		buffer[i] = byte(value) | 0x80
+		value >>= 7
+		i++
+	}
+	buffer[i] = byte(value)
+	return i + 1
+}
+
+// byteSource 为逐字节读取提供统一的入口
+// 如果读取器实现了 io.ByteReader，则直接使用它以避免额外的缓冲
+type byteSource struct {
+	reader     io.Reader
+	byteReader io.ByteReader
+	single     []byte
+}
+
+// newByteSource 创建逐字节读取器，单字节缓冲区优先从 scratch arena 获取
+func newByteSource(reader io.Reader, scratch *scratchArena) byteSource {
+	source := byteSource{reader: reader}
+	if byteReader, ok := reader.(io.ByteReader); ok {
+		source.byteReader = byteReader
+	} else if scratch != nil {
+		source.single = scratch.Get(1)
+	} else {
+		source.single = make([]byte, 1)
+	}
+	return source
+}
+
+// ReadByte 读取一个字节
+func (s *byteSource) ReadByte() (byte, error) {
+	if s.byteReader != nil {
+		return s.byteReader.ReadByte()
+	}
+	if _, err := io.ReadFull(s.reader, s.single); err != nil {
+		return 0, err
+	}
+	return s.single[0], nil
+}
+
+// readVarint 从读取器中逐字节读取一个变长整数
+func readVarint(reader io.Reader, t Type, scratch *scratchArena) (uint64, error) {
+	source := newByteSource(reader, scratch)
+
+	var result uint64
+	var shift uint
+	for i := 0; i < maxVarintLen; i++ {
+		b, err := source.ReadByte()
+		if err != nil {
+			if i > 0 && err == io.EOF {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+
+		if i == maxVarintLen-1 && t != Sleb128 && b > 1 {
+			return 0, errVarintOverflow
+		}
+		result |= uint64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 != 0 {
+			continue
+		}
+
+		switch t {
+		case Varint:
+			return uint64(int64(result>>1) ^ -int64(result&1)), nil
+		case Sleb128:
+			if shift < 64 && b&0x40 != 0 {
+				result |= ^uint64(0) << shift
+			}
+		}
+		return result, nil
+	}
+	return 0, errVarintOverflow
+}
+
+// calculateVarintSize 计算变长整数字段（或切片）编码后的字节数
+func (f *Field) calculateVarintSize(fieldValue reflect.Value, resolvedType Type) int {
+	if f.IsPointer {
+		fieldValue = fieldValue.Elem()
+	}
+	if !f.IsSlice {
+		return varintSize(resolvedType, f.getIntegerValue(fieldValue))
+	}
+
+	dataLength := fieldValue.Len()
+	length := dataLength
+	if f.Length > 0 {
+		length = f.Length
+	}
+	totalSize := 0
+	for i := 0; i < length; i++ {
+		if i < dataLength {
+			totalSize += varintSize(resolvedType, f.getIntegerValue(fieldValue.Index(i)))
+		} else {
+			totalSize++ // 零值编码为单字节
+		}
+	}
+	return totalSize
+}
+
+// packVarint 将变长整数字段（或切片）打包到缓冲区
+func (f *Field) packVarint(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type) (int, error) {
+	if f.IsPointer {
+		fieldValue = fieldValue.Elem()
+	}
+	if !f.IsSlice {
+		return putVarint(buffer, resolvedType, f.getIntegerValue(fieldValue)), nil
+	}
+
+	dataLength := fieldValue.Len()
+	position := 0
+	for i := 0; i < length; i++ {
+		var value uint64
+		if i < dataLength {
+			value = f.getIntegerValue(fieldValue.Index(i))
+		}
+		position += putVarint(buffer[position:], resolvedType, value)
+	}
+	return position, nil
+}
+
+// unpackVarint 从读取器中解包变长整数字段（或切片）
+func (f *Field) unpackVarint(reader io.Reader, fieldValue reflect.Value, length int, resolvedType Type, scratch *scratchArena) error {
+	if f.IsPointer {
+		fieldValue = fieldValue.Elem()
+	}
+	if !f.IsSlice {
+		value, err := readVarint(reader, resolvedType, scratch)
+		if err != nil {
+			return err
+		}
+		return f.setVarintValue(fieldValue, value, resolvedType)
+	}
+
+	if !f.IsArray {
+		if fieldValue.Cap() < length {
+			fieldValue.Set(reflect.MakeSlice(fieldValue.Type(), length, length))
+		} else {
+			fieldValue.Set(fieldValue.Slice(0, length))
+		}
+	}
+	for i := 0; i < length; i++ {
+		value, err := readVarint(reader, resolvedType, scratch)
+		if err != nil {
+			return fmt.Errorf("failed to unpack slice element %d: %w", i, err)
+		}
+		if err := f.setVarintValue(fieldValue.Index(i), value, resolvedType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unpackVarintBuffer 从缓冲区中解包变长整数字段（或切片）
+func (f *Field) unpackVarintBuffer(buffer []byte, fieldValue reflect.Value, length int, resolvedType Type) error {
+	return f.unpackVarint(bytes.NewReader(buffer), fieldValue, length, resolvedType, nil)
+}
+
+// setVarintValue 将解码后的变长整数写入字段，超出字段范围时报错
+func (f *Field) setVarintValue(fieldValue reflect.Value, value uint64, resolvedType Type) error {
+	switch f.kind {
+	case reflect.Bool:
+		fieldValue.SetBool(value != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fieldValue.OverflowInt(int64(value)) {
+			return fmt.Errorf("struc: %s value %d overflows %v (field `%s`)", resolvedType, int64(value), fieldValue.Type(), f.Name)
+		}
+		fieldValue.SetInt(int64(value))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if fieldValue.OverflowUint(value) {
+			return fmt.Errorf("struc: %s value %d overflows %v (field `%s`)", resolvedType, value, fieldValue.Type(), f.Name)
+		}
+		fieldValue.SetUint(value)
+	default:
+		return fmt.Errorf("struc: cannot unpack varint into field %s of type %s", f.Name, f.kind)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package struc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type varintExample struct {
+	U   uint64 `struc:"uvarint"`
+	V   int    `struc:"varint"`
+	S   int64  `struc:"sleb128"`
+	L   int    `struc:"uleb128"`
+	Arr []int  `struc:"[3]varint"`
+}
+
+func TestVarintEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	in := &varintExample{U: 300, V: -1, S: -123456, L: 127, Arr: []int{1, -2, 64}}
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0xac, 0x02, // uvarint(300)
+		0x01,             // zigzag(-1)
+		0xc0, 0xbb, 0x78, // sleb128(-123456)
+		0x7f,                   // uleb128(127)
+		0x02, 0x03, 0x80, 0x01, // zigzag(1, -2, 64)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("varint pack: got %x, want %x", buf.Bytes(), want)
+	}
+	if size, err := Sizeof(in); err != nil || size != len(want) {
+		t.Fatalf("varint sizeof: got %d (%v), want %d", size, err, len(want))
+	}
+
+	out := &varintExample{}
+	if err := Unpack(iotest.OneByteReader(bytes.NewReader(want)), out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("varint round trip: got %+v, want %+v", out, in)
+	}
+}
+
+type varintSizeof struct {
+	Len  int `struc:"uvarint,sizeof=Data"`
+	Data []byte
+	Tail uint8
+}
+
+func TestVarintSizeof(t *testing.T) {
+	var buf bytes.Buffer
+	in := &varintSizeof{Data: bytes.Repeat([]byte{0xaa}, 200), Tail: 7}
+	size, err := Sizeof(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 2+200+1 {
+		t.Fatalf("varint sizeof: got %d, want %d", size, 203)
+	}
+	if err := Pack(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != size || buf.Bytes()[0] != 0xc8 || buf.Bytes()[1] != 0x01 {
+		t.Fatalf("varint sizeof pack: got %x", buf.Bytes()[:2])
+	}
+	out := &varintSizeof{}
+	if err := Unpack(&buf, out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len != 200 || !bytes.Equal(out.Data, in.Data) || out.Tail != 7 {
+		t.Fatalf("varint sizeof round trip failed: %+v", out)
+	}
+}
+
+type varintSingle struct {
+	U uint64 `struc:"uvarint"`
+}
+
+func TestVarintErrors(t *testing.T) {
+	overflow := bytes.Repeat([]byte{0xff}, 11)
+	if err := Unpack(bytes.NewReader(overflow), &varintSingle{}); err == nil {
+		t.Fatal("failed to error on varint overflow")
+	}
+	if err := Unpack(bytes.NewReader([]byte{0x80}), &varintSingle{}); err == nil {
+		t.Fatal("failed to error on truncated varint")
+	}
+
+	narrow := []struct {
+		name string
+		data []byte
+		out  interface{}
+	}{
+		{"uvarint into uint8", []byte{0xac, 0x02}, &struct {
+			V uint8 `struc:"uvarint"`
+		}{}},
+		{"varint into int8", []byte{0x80, 0x02}, &struct {
+			V int8 `struc:"varint"`
+		}{}},
+		{"sleb128 into int16", []byte{0x80, 0x80, 0x7d}, &struct {
+			V int16 `struc:"sleb128"`
+		}{}},
+		{"uvarint slice into uint16", []byte{0x01, 0x80, 0x80, 0x04}, &struct {
+			V []uint16 `struc:"[2]uvarint"`
+		}{}},
+	}
+	for _, tt := range narrow {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unpack(bytes.NewReader(tt.data), tt.out)
+			if err == nil || !strings.Contains(err.Error(), "overflows") {
+				t.Fatalf("expected overflow error, got %v", err)
+			}
+		})
+	}
+}